@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
@@ -11,19 +14,79 @@ import (
 	"gorm.io/gorm"
 )
 
+// Modos de sessão suportados
+const (
+	// SessionModeBearer entrega os tokens ao frontend, que os envia no cabeçalho Authorization
+	SessionModeBearer = "bearer"
+	// SessionModeCookie mantém a sessão em cookies HttpOnly protegidos por token CSRF
+	SessionModeCookie = "cookie"
+)
+
+// Modos de autorização suportados
+const (
+	// AuthzModeToken confia nos papéis e permissões gravados no token de acesso
+	AuthzModeToken = "token"
+	// AuthzModeLive resolve papéis e permissões no banco a cada requisição, com cache por usuário
+	AuthzModeLive = "live"
+)
+
 // Config armazena as configurações da aplicação
 type Config struct {
-	ServerPort       string
-	DBHost           string
-	DBPort           string
-	DBUser           string
-	DBPassword       string
-	DBName           string
-	JWTSecret        string
-	GoogleClientID   string
-	GoogleClientSecret string
-	GoogleRedirectURL string
-	FrontendURL      string
+	ServerPort               string
+	DBHost                   string
+	DBPort                   string
+	DBUser                   string
+	DBPassword               string
+	DBName                   string
+	JWTSecret                string
+	JWTKeyEncryptionSecret   string
+	JWTKeyEncryptionPrevious []string
+	JWTSigningAlg            string
+	JWTPrivateKeyFile        string
+	JWTKeyID                 string
+	JWTKeyRotationInterval   time.Duration
+	JWTKeyGracePeriod        time.Duration
+	GoogleClientID           string
+	GoogleClientSecret       string
+	GoogleRedirectURL        string
+	GoogleIssuer             string
+	GoogleJWKSURL            string
+	GitHubClientID           string
+	GitHubClientSecret       string
+	GitHubRedirectURL        string
+	MicrosoftTenantID        string
+	MicrosoftClientID        string
+	MicrosoftClientSecret    string
+	MicrosoftRedirectURL     string
+	OIDCProviderName         string
+	OIDCIssuer               string
+	OIDCClientID             string
+	OIDCClientSecret         string
+	OIDCRedirectURL          string
+	OIDCScopes               string
+	OIDCRequireVerifiedEmail bool
+	FrontendURL              string
+	SessionMode              string
+	CookieDomain             string
+	CookieSameSite           http.SameSite
+	AuthzMode                string
+	ServiceClients           map[string]string
+	RelationSchemaFile       string
+	OAuthPKCEEnabled         bool
+	AllowedHostedDomains     []string
+	AllowedEmailDomains      []string
+	DeniedEmailDomains       []string
+	AllowedEmails            []string
+	SignInInviteOnly         bool
+	PlatformAdmins           []string
+	TrustedProxies           []string
+	MailSender               string
+	MailFrom                 string
+	MailFileDir              string
+	SMTPHost                 string
+	SMTPPort                 string
+	SMTPUsername             string
+	SMTPPassword             string
 }
 
 // LoadConfig carrega as configurações do arquivo .env
@@ -34,27 +97,160 @@ func LoadConfig() (*Config, error) {
 	}
 
 	config := &Config{
-		ServerPort:         os.Getenv("SERVER_PORT"),
-		DBHost:             os.Getenv("DB_HOST"),
-		DBPort:             os.Getenv("DB_PORT"),
-		DBUser:             os.Getenv("DB_USER"),
-		DBPassword:         os.Getenv("DB_PASSWORD"),
-		DBName:             os.Getenv("DB_NAME"),
-		JWTSecret:          os.Getenv("JWT_SECRET"),
-		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		GoogleRedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
-		FrontendURL:        os.Getenv("FRONTEND_URL"),
+		ServerPort:               os.Getenv("SERVER_PORT"),
+		DBHost:                   os.Getenv("DB_HOST"),
+		DBPort:                   os.Getenv("DB_PORT"),
+		DBUser:                   os.Getenv("DB_USER"),
+		DBPassword:               os.Getenv("DB_PASSWORD"),
+		DBName:                   os.Getenv("DB_NAME"),
+		JWTSecret:                os.Getenv("JWT_SECRET"),
+		JWTKeyEncryptionSecret:   os.Getenv("JWT_KEY_ENCRYPTION_SECRET"),
+		JWTKeyEncryptionPrevious: splitList(os.Getenv("JWT_KEY_ENCRYPTION_PREVIOUS_SECRETS")),
+		JWTSigningAlg:            os.Getenv("JWT_SIGNING_ALG"),
+		JWTPrivateKeyFile:        os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		JWTKeyID:                 os.Getenv("JWT_KEY_ID"),
+		GoogleClientID:           os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret:       os.Getenv("GOOGLE_CLIENT_SECRET"),
+		GoogleRedirectURL:        os.Getenv("GOOGLE_REDIRECT_URL"),
+		GoogleIssuer:             os.Getenv("GOOGLE_ISSUER"),
+		GoogleJWKSURL:            os.Getenv("GOOGLE_JWKS_URL"),
+		GitHubClientID:           os.Getenv("GITHUB_CLIENT_ID"),
+		GitHubClientSecret:       os.Getenv("GITHUB_CLIENT_SECRET"),
+		GitHubRedirectURL:        os.Getenv("GITHUB_REDIRECT_URL"),
+		MicrosoftTenantID:        os.Getenv("MICROSOFT_TENANT_ID"),
+		MicrosoftClientID:        os.Getenv("MICROSOFT_CLIENT_ID"),
+		MicrosoftClientSecret:    os.Getenv("MICROSOFT_CLIENT_SECRET"),
+		MicrosoftRedirectURL:     os.Getenv("MICROSOFT_REDIRECT_URL"),
+		OIDCProviderName:         os.Getenv("OIDC_PROVIDER_NAME"),
+		OIDCIssuer:               os.Getenv("OIDC_ISSUER"),
+		OIDCClientID:             os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:         os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:          os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCScopes:               os.Getenv("OIDC_SCOPES"),
+		OIDCRequireVerifiedEmail: os.Getenv("OIDC_REQUIRE_VERIFIED_EMAIL") != "false",
+		FrontendURL:              os.Getenv("FRONTEND_URL"),
+		SessionMode:              os.Getenv("AUTH_SESSION_MODE"),
+		CookieDomain:             os.Getenv("AUTH_COOKIE_DOMAIN"),
+		AuthzMode:                os.Getenv("AUTHZ_MODE"),
+		RelationSchemaFile:       os.Getenv("REBAC_SCHEMA_FILE"),
+		OAuthPKCEEnabled:         os.Getenv("OAUTH_PKCE_ENABLED") != "false",
+		AllowedHostedDomains:     splitList(os.Getenv("GOOGLE_ALLOWED_HOSTED_DOMAINS")),
+		AllowedEmailDomains:      splitList(os.Getenv("SIGNIN_ALLOWED_EMAIL_DOMAINS")),
+		DeniedEmailDomains:       splitList(os.Getenv("SIGNIN_DENIED_EMAIL_DOMAINS")),
+		AllowedEmails:            splitList(os.Getenv("SIGNIN_ALLOWED_EMAILS")),
+		SignInInviteOnly:         os.Getenv("SIGNIN_INVITE_ONLY") == "true",
+		PlatformAdmins:           splitList(os.Getenv("PLATFORM_ADMIN_EMAILS")),
+		TrustedProxies:           splitList(os.Getenv("TRUSTED_PROXIES")),
+		MailSender:               os.Getenv("MAIL_SENDER"),
+		MailFrom:                 os.Getenv("MAIL_FROM"),
+		MailFileDir:              os.Getenv("MAIL_FILE_DIR"),
+		SMTPHost:                 os.Getenv("SMTP_HOST"),
+		SMTPPort:                 os.Getenv("SMTP_PORT"),
+		SMTPUsername:             os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:             os.Getenv("SMTP_PASSWORD"),
 	}
 
 	// Definir valores padrão se não estiverem definidos
 	if config.ServerPort == "" {
 		config.ServerPort = "8080"
 	}
+	config.JWTKeyRotationInterval, err = parseDuration(os.Getenv("JWT_KEY_ROTATION_INTERVAL"), 30*24*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEY_ROTATION_INTERVAL inválido: %w", err)
+	}
+	config.JWTKeyGracePeriod, err = parseDuration(os.Getenv("JWT_KEY_GRACE_PERIOD"), 7*24*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEY_GRACE_PERIOD inválido: %w", err)
+	}
+	config.CookieSameSite, err = parseSameSite(os.Getenv("AUTH_COOKIE_SAMESITE"))
+	if err != nil {
+		return nil, err
+	}
+	if config.SessionMode == "" {
+		config.SessionMode = SessionModeBearer
+	}
+	if config.SessionMode != SessionModeBearer && config.SessionMode != SessionModeCookie {
+		return nil, fmt.Errorf("AUTH_SESSION_MODE inválido: %s", config.SessionMode)
+	}
+	config.ServiceClients, err = parseServiceClients(os.Getenv("AUTHZ_SERVICE_CLIENTS"))
+	if err != nil {
+		return nil, err
+	}
+	if config.AuthzMode == "" {
+		config.AuthzMode = AuthzModeToken
+	}
+	if config.AuthzMode != AuthzModeToken && config.AuthzMode != AuthzModeLive {
+		return nil, fmt.Errorf("AUTHZ_MODE inválido: %s", config.AuthzMode)
+	}
+	// Instalações anteriores cifravam as chaves de assinatura com o JWT_SECRET
+	if config.JWTKeyEncryptionSecret == "" {
+		config.JWTKeyEncryptionSecret = config.JWTSecret
+	}
+	if config.JWTSigningAlg == "" {
+		config.JWTSigningAlg = "RS256"
+	}
+	if config.GoogleIssuer == "" {
+		config.GoogleIssuer = googleIssuer
+	}
+	if config.GoogleJWKSURL == "" {
+		config.GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+	}
+	if config.OIDCProviderName == "" {
+		config.OIDCProviderName = "oidc"
+	}
 
 	return config, nil
 }
 
+// splitList converte uma lista separada por vírgulas em slice, ignorando itens vazios
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDuration interpreta uma duração no formato do Go (ex.: 720h), usando o
+// valor padrão quando vazia
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseSameSite interpreta a política SameSite dos cookies de sessão (padrão lax)
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("AUTH_COOKIE_SAMESITE inválido: %s", value)
+	}
+}
+
+// parseServiceClients interpreta as credenciais dos serviços que consultam a API
+// de autorização, no formato id:segredo separados por vírgulas
+func parseServiceClients(value string) (map[string]string, error) {
+	clients := make(map[string]string)
+	for _, item := range splitList(value) {
+		id, secret, ok := strings.Cut(item, ":")
+		id, secret = strings.TrimSpace(id), strings.TrimSpace(secret)
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("AUTHZ_SERVICE_CLIENTS inválido: use id:segredo separados por vírgulas")
+		}
+		clients[id] = secret
+	}
+	return clients, nil
+}
+
 // SetupDatabase configura a conexão com o banco de dados
 func SetupDatabase(cfg *Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -68,6 +264,28 @@ func SetupDatabase(cfg *Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// googleIssuer é o issuer canônico dos ID tokens emitidos pelo Google
+const googleIssuer = "https://accounts.google.com"
+
+// CookieSessions indica se a sessão é mantida em cookies HttpOnly
+func (c *Config) CookieSessions() bool {
+	return c.SessionMode == SessionModeCookie
+}
+
+// LiveAuthz indica se as permissões são resolvidas no banco em vez de lidas do token
+func (c *Config) LiveAuthz() bool {
+	return c.AuthzMode == AuthzModeLive
+}
+
+// GoogleIssuers retorna os valores de iss aceitos nos ID tokens do Google.
+// O Google também emite tokens com o issuer sem esquema.
+func (c *Config) GoogleIssuers() []string {
+	if c.GoogleIssuer == googleIssuer {
+		return []string{googleIssuer, "accounts.google.com"}
+	}
+	return []string{c.GoogleIssuer}
+}
+
 // GetGoogleOAuthConfig retorna a configuração para autenticação com Google OAuth
 func GetGoogleOAuthConfig(cfg *Config) *oauth2.Config {
 	return &oauth2.Config{
@@ -75,9 +293,10 @@ func GetGoogleOAuthConfig(cfg *Config) *oauth2.Config {
 		ClientSecret: cfg.GoogleClientSecret,
 		RedirectURL:  cfg.GoogleRedirectURL,
 		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
+			"openid",
+			"email",
+			"profile",
 		},
 		Endpoint: google.Endpoint,
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
-	// "go-google/models"
+	"errors"
+	"go-google/middleware"
+	"go-google/models"
+	"go-google/providers"
 	"go-google/services"
 	"net/http"
 
@@ -20,47 +23,318 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 	}
 }
 
+const (
+	// stateCookieName é o cookie que vincula o state OAuth ao navegador que iniciou o login
+	stateCookieName = "oauth_state"
+	// refreshCookieName é o cookie do token de atualização no modo de cookies
+	refreshCookieName = "refresh_token"
+)
+
+// ListProviders lista os provedores de identidade habilitados
+func (h *AuthHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.authService.ListProviders()})
+}
+
+// Login inicia o fluxo de login com o provedor informado na rota
+func (h *AuthHandler) Login(c *gin.Context) {
+	h.login(c, c.Param("provider"))
+}
+
+// Callback processa o callback do provedor informado na rota
+func (h *AuthHandler) Callback(c *gin.Context) {
+	h.callback(c, c.Param("provider"))
+}
+
 // GoogleLogin inicia o fluxo de login com Google OAuth
 func (h *AuthHandler) GoogleLogin(c *gin.Context) {
-	url := h.authService.GetGoogleAuthURL()
-	c.JSON(http.StatusOK, gin.H{"url": url})
+	h.login(c, providers.Google)
 }
 
 // GoogleCallback processa o callback do Google OAuth
 func (h *AuthHandler) GoogleCallback(c *gin.Context) {
+	h.callback(c, providers.Google)
+}
+
+// login emite o state e retorna a URL de autorização do provedor. O parâmetro
+// invitation recebe o token do link de convite a ser aceito no login.
+func (h *AuthHandler) login(c *gin.Context, provider string) {
+	url, state, err := h.authService.GetAuthURL(provider, c.Query("return_to"), c.Query("invitation"))
+	if err != nil {
+		switch {
+		case errors.Is(err, providers.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidInvitation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvitationNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	h.setStateCookie(c, state, int(services.LoginStateTTL.Seconds()))
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// callback valida o retorno do provedor e redireciona para o frontend
+func (h *AuthHandler) callback(c *gin.Context, provider string) {
+	boundState, _ := c.Cookie(stateCookieName)
+	// O state é de uso único, então o cookie é descartado em qualquer caso
+	h.setStateCookie(c, "", -1)
+
 	code := c.Query("code")
 	if code == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Código de autorização ausente"})
 		return
 	}
 
-	userWithToken, err := h.authService.ProcessGoogleCallback(code)
+	userWithToken, returnTo, err := h.authService.ProcessCallback(provider, code, c.Query("state"), boundState, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, providers.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidState), errors.Is(err, services.ErrInvalidInvitation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSignInDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.SignInDeniedCode})
+		case errors.Is(err, providers.ErrEmailNotVerified), errors.Is(err, services.ErrInvitationEmailMismatch):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailInUse), errors.Is(err, services.ErrIdentityLinked), errors.Is(err, services.ErrInvitationNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// No modo de cookies, a sessão é gravada diretamente nos cookies
+	if h.authService.CookieSessions() {
+		if err := h.setSessionCookies(c, userWithToken); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Redirect(http.StatusTemporaryRedirect, h.authService.GetFrontendSessionURL(returnTo))
 		return
 	}
 
-	// Redirecionar para o frontend com token
-	redirectURL := h.authService.GetFrontendRedirectURL(userWithToken.AccessToken, userWithToken.RefreshToken)
+	// Redirecionar para o frontend com um código de troca de uso único
+	redirectURL, err := h.authService.GetFrontendRedirectURL(userWithToken, returnTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.Redirect(http.StatusTemporaryRedirect, redirectURL)
 }
 
+// Exchange troca o código recebido no redirecionamento pelo resultado do login.
+// No modo de cookies, os tokens são gravados em cookies HttpOnly em vez de
+// retornados no corpo da resposta.
+func (h *AuthHandler) Exchange(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Código de troca ausente"})
+		return
+	}
+
+	userWithToken, err := h.authService.ExchangeHandoffCode(req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidHandoffCode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithTokens(c, userWithToken)
+}
+
+// Logout encerra a sessão do token usado na requisição
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authService.Logout(c.GetString("userID"), c.GetString("sessionID")); err != nil {
+		if errors.Is(err, services.ErrNoSession) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.clearTokenCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada com sucesso"})
+}
+
+// LogoutAll encerra todas as sessões do usuário autenticado
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	if err := h.authService.LogoutAll(c.GetString("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.clearTokenCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Todas as sessões foram encerradas"})
+}
+
+// RevokeToken implementa o endpoint de revogação da RFC 7009
+func (h *AuthHandler) RevokeToken(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "parâmetro token ausente"})
+		return
+	}
+
+	if err := h.authService.RevokeToken(token, c.PostForm("token_type_hint")); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "temporarily_unavailable"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// clearTokenCookies remove os cookies da sessão no modo de cookies
+func (h *AuthHandler) clearTokenCookies(c *gin.Context) {
+	if !h.authService.CookieSessions() {
+		return
+	}
+	opts := h.authService.SessionCookieOptions()
+	c.SetSameSite(opts.SameSite)
+	c.SetCookie(middleware.AccessCookieName, "", -1, "/", opts.Domain, opts.Secure, true)
+	c.SetCookie(refreshCookieName, "", -1, "/auth", opts.Domain, opts.Secure, true)
+	c.SetCookie(middleware.CSRFCookieName, "", -1, "/", opts.Domain, opts.Secure, false)
+}
+
+// clientInfo extrai os metadados do dispositivo que fez a requisição
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// setStateCookie grava (ou remove, com maxAge negativo) o cookie do state OAuth
+func (h *AuthHandler) setStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookieName, state, maxAge, "/auth", "", h.authService.SecureCookies(), true)
+}
+
 // RefreshToken atualiza o token de acesso usando um token de atualização
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req struct {
-		RefreshToken string `json:"refresh_token" binding:"required"`
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Token de atualização inválido"})
+			return
+		}
+	}
+
+	refreshToken, ok := h.refreshTokenFrom(c, req.RefreshToken)
+	if !ok {
+		return
+	}
+
+	userWithToken, err := h.authService.RefreshToken(refreshToken, clientInfo(c))
+	if err != nil {
+		h.refreshError(c, err)
+		return
 	}
 
+	h.respondWithTokens(c, userWithToken)
+}
+
+// SwitchOrganization troca a organização ativa da sessão, emitindo novos tokens
+// a partir do token de atualização
+func (h *AuthHandler) SwitchOrganization(c *gin.Context) {
+	var req models.OrganizationSwitchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Token de atualização inválido"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	userWithToken, err := h.authService.RefreshToken(req.RefreshToken)
+	refreshToken, ok := h.refreshTokenFrom(c, req.RefreshToken)
+	if !ok {
+		return
+	}
+
+	userWithToken, err := h.authService.SwitchOrganization(refreshToken, req.OrganizationID, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		if errors.Is(err, services.ErrNotOrganizationMember) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		h.refreshError(c, err)
+		return
+	}
+
+	h.respondWithTokens(c, userWithToken)
+}
+
+// refreshTokenFrom retorna o token de atualização do corpo ou, no modo de
+// cookies, do cookie HttpOnly. Como o navegador envia o cookie automaticamente,
+// o uso dele exige o token CSRF. Responde com o erro quando não há token válido.
+func (h *AuthHandler) refreshTokenFrom(c *gin.Context, refreshToken string) (string, bool) {
+	if refreshToken == "" && h.authService.CookieSessions() {
+		refreshToken, _ = c.Cookie(refreshCookieName)
+		if refreshToken != "" && !middleware.ValidCSRF(c, h.authService, h.authService.SessionID(refreshToken)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token CSRF inválido", "code": "csrf_invalid"})
+			return "", false
+		}
+	}
+	if refreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token de atualização inválido"})
+		return "", false
+	}
+	return refreshToken, true
+}
+
+// refreshError traduz os erros da renovação de tokens para respostas HTTP
+func (h *AuthHandler) refreshError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "refresh_token_reused"})
 		return
 	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
+
+// respondWithTokens entrega os tokens no corpo da resposta ou, no modo de
+// cookies, em cookies HttpOnly, retornando apenas o perfil, a validade e o token CSRF
+func (h *AuthHandler) respondWithTokens(c *gin.Context, userWithToken *models.UserWithToken) {
+	c.Header("Cache-Control", "no-store")
 
-	c.JSON(http.StatusOK, userWithToken)
-}
\ No newline at end of file
+	if !h.authService.CookieSessions() {
+		c.JSON(http.StatusOK, userWithToken)
+		return
+	}
+
+	if err := h.setSessionCookies(c, userWithToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":       userWithToken.User,
+		"expires_in": userWithToken.ExpiresIn,
+	})
+}
+
+// setSessionCookies grava os tokens em cookies HttpOnly e emite um novo token
+// CSRF para a sessão, em um cookie legível pelo frontend (double-submit)
+func (h *AuthHandler) setSessionCookies(c *gin.Context, userWithToken *models.UserWithToken) error {
+	csrfToken, err := h.authService.IssueCSRFToken(h.authService.SessionID(userWithToken.AccessToken))
+	if err != nil {
+		return err
+	}
+
+	opts := h.authService.SessionCookieOptions()
+	c.SetSameSite(opts.SameSite)
+	c.SetCookie(middleware.AccessCookieName, userWithToken.AccessToken, int(services.AccessTokenTTL.Seconds()), "/", opts.Domain, opts.Secure, true)
+	c.SetCookie(refreshCookieName, userWithToken.RefreshToken, int(services.RefreshTokenTTL.Seconds()), "/auth", opts.Domain, opts.Secure, true)
+	c.SetCookie(middleware.CSRFCookieName, csrfToken, int(services.RefreshTokenTTL.Seconds()), "/", opts.Domain, opts.Secure, false)
+	return nil
+}
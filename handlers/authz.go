@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthzHandler responde às verificações de autorização feitas por outros serviços
+type AuthzHandler struct {
+	authzService *services.AuthzService
+}
+
+// NewAuthzHandler cria uma nova instância do manipulador de verificações de autorização
+func NewAuthzHandler(authzService *services.AuthzService) *AuthzHandler {
+	return &AuthzHandler{
+		authzService: authzService,
+	}
+}
+
+// Check responde se o usuário pode realizar a ação, com o motivo da decisão
+func (h *AuthzHandler) Check(c *gin.Context) {
+	var req models.AuthzCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	decision, err := h.authzService.Check(req)
+	if err != nil {
+		h.checkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// CheckMany responde várias verificações de uma vez, na ordem recebida
+func (h *AuthzHandler) CheckMany(c *gin.Context) {
+	var req models.AuthzCheckManyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	decisions, err := h.authzService.CheckMany(req.Checks)
+	if err != nil {
+		h.checkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": decisions})
+}
+
+// checkError traduz os erros das verificações para respostas HTTP
+func (h *AuthzHandler) checkError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidAuthzCheck) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExplainHandler manipula as consultas de permissões efetivas dos usuários
+type ExplainHandler struct {
+	explainService *services.ExplainService
+}
+
+// NewExplainHandler cria uma nova instância do manipulador de permissões efetivas
+func NewExplainHandler(explainService *services.ExplainService) *ExplainHandler {
+	return &ExplainHandler{
+		explainService: explainService,
+	}
+}
+
+// EffectivePermissions retorna as permissões efetivas de um usuário. Com
+// explain=true, inclui os caminhos que concedem cada permissão; com add_group e
+// remove_group, simula a inclusão ou remoção do usuário nos grupos.
+func (h *ExplainHandler) EffectivePermissions(c *gin.Context) {
+	query := models.EffectivePermissionsQuery{
+		AddGroups:    c.QueryArray("add_group"),
+		RemoveGroups: c.QueryArray("remove_group"),
+	}
+	if explain := c.Query("explain"); explain != "" {
+		value, err := strconv.ParseBool(explain)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Valor inválido para explain"})
+			return
+		}
+		query.Explain = value
+	}
+
+	result, err := h.explainService.EffectivePermissions(organizationID(c), c.Param("id"), query)
+	if err != nil {
+		h.explainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// explainError traduz os erros da consulta de permissões efetivas para respostas HTTP
+func (h *ExplainHandler) explainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrGroupNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
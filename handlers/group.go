@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GroupHandler manipula requisições relacionadas a grupos e seus membros
+type GroupHandler struct {
+	groupService *services.GroupService
+}
+
+// NewGroupHandler cria uma nova instância do manipulador de grupos
+func NewGroupHandler(groupService *services.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// ListGroups lista os grupos da organização ativa
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.groupService.ListGroups(organizationID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroup retorna um grupo com seus papéis
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	group, err := h.groupService.GetGroup(organizationID(c), c.Param("id"))
+	if err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// CreateGroup cria um novo grupo
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req models.GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(organizationID(c), req)
+	if err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateGroup atualiza os dados de um grupo
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	var req models.GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(organizationID(c), c.Param("id"), req)
+	if err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup exclui um grupo
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	if err := h.groupService.DeleteGroup(organizationID(c), c.Param("id")); err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grupo excluído com sucesso"})
+}
+
+// AssignRoles substitui os papéis de um grupo
+func (h *GroupHandler) AssignRoles(c *gin.Context) {
+	var req struct {
+		RoleIDs []string `json:"role_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := h.groupService.AssignRoles(organizationID(c), c.Param("id"), req.RoleIDs)
+	if err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// ListMembers lista os membros de um grupo
+func (h *GroupHandler) ListMembers(c *gin.Context) {
+	members, err := h.groupService.ListMembers(organizationID(c), c.Param("id"))
+	if err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember adiciona um usuário ao grupo sem alterar suas outras associações
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	if err := h.groupService.AddMember(organizationID(c), c.Param("id"), c.Param("userId")); err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário adicionado ao grupo com sucesso"})
+}
+
+// RemoveMember remove um usuário do grupo
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	if err := h.groupService.RemoveMember(organizationID(c), c.Param("id"), c.Param("userId")); err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário removido do grupo com sucesso"})
+}
+
+// DelegatedAddMember adiciona um usuário ao grupo pela rota acessível a quem
+// tem um papel atribuído sobre o grupo
+func (h *GroupHandler) DelegatedAddMember(c *gin.Context) {
+	if err := h.groupService.DelegatedAddMember(organizationID(c), c.GetString("userID"), c.Param("id"), c.Param("userId")); err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário adicionado ao grupo com sucesso"})
+}
+
+// DelegatedRemoveMember remove um usuário do grupo pela rota acessível a quem
+// tem um papel atribuído sobre o grupo
+func (h *GroupHandler) DelegatedRemoveMember(c *gin.Context) {
+	if err := h.groupService.DelegatedRemoveMember(organizationID(c), c.GetString("userID"), c.Param("id"), c.Param("userId")); err != nil {
+		h.groupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário removido do grupo com sucesso"})
+}
+
+// groupError traduz os erros de grupos para respostas HTTP
+func (h *GroupHandler) groupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrGroupNotFound), errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrParentGroupNotFound), errors.Is(err, services.ErrGroupCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrGroupExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDelegationExceeded):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
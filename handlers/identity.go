@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/providers"
+	"go-google/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityHandler manipula requisições relacionadas às identidades vinculadas do usuário
+type IdentityHandler struct {
+	authService *services.AuthService
+}
+
+// NewIdentityHandler cria uma nova instância do manipulador de identidades
+func NewIdentityHandler(authService *services.AuthService) *IdentityHandler {
+	return &IdentityHandler{
+		authService: authService,
+	}
+}
+
+// ListIdentities lista as identidades vinculadas ao usuário autenticado
+func (h *IdentityHandler) ListIdentities(c *gin.Context) {
+	identities, err := h.authService.ListIdentities(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// LinkIdentity inicia o vínculo de uma identidade do provedor informado à conta autenticada
+func (h *IdentityHandler) LinkIdentity(c *gin.Context) {
+	authTime := time.Unix(c.GetInt64("authTime"), 0)
+	url, state, err := h.authService.StartIdentityLink(c.GetString("userID"), c.Param("provider"), c.Query("return_to"), authTime)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReauthRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "reauth_required"})
+		case errors.Is(err, providers.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookieName, state, int(services.LoginStateTTL.Seconds()), "/auth", "", h.authService.SecureCookies(), true)
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// UnlinkIdentity desvincula uma identidade da conta autenticada
+func (h *IdentityHandler) UnlinkIdentity(c *gin.Context) {
+	err := h.authService.UnlinkIdentity(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIdentityNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrLastIdentity):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identidade desvinculada com sucesso"})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvitationHandler manipula requisições relacionadas aos convites da organização
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+}
+
+// NewInvitationHandler cria uma nova instância do manipulador de convites
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+	}
+}
+
+// ListInvitations lista os convites da organização ativa
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.ListInvitations(organizationID(c))
+	if err != nil {
+		h.invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// CreateInvitation convida um e-mail para a organização ativa e envia o link do convite
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req models.InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(organizationID(c), c.GetString("userID"), req)
+	if err != nil {
+		h.invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ResendInvitation emite um novo link para o convite e o envia novamente
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	invitation, err := h.invitationService.ResendInvitation(organizationID(c), c.Param("id"))
+	if err != nil {
+		h.invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+// RevokeInvitation revoga um convite pendente
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	if err := h.invitationService.RevokeInvitation(organizationID(c), c.Param("id")); err != nil {
+		h.invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Convite revogado com sucesso"})
+}
+
+// invitationError traduz os erros de convites para respostas HTTP
+func (h *InvitationHandler) invitationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvitationNotFound), errors.Is(err, services.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrGroupNotFound), errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvitationExists), errors.Is(err, services.ErrInvitationNotPending), errors.Is(err, services.ErrMemberExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeysHandler publica as chaves públicas usadas para verificar os tokens emitidos
+type KeysHandler struct {
+	keyService *services.KeyService
+}
+
+// NewKeysHandler cria uma nova instância do manipulador de chaves
+func NewKeysHandler(keyService *services.KeyService) *KeysHandler {
+	return &KeysHandler{
+		keyService: keyService,
+	}
+}
+
+// JWKS retorna o documento JWKS com as chaves de verificação ativas
+func (h *KeysHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keyService.JWKS())
+}
+
+// ListKeys lista os metadados das chaves de assinatura (apenas para administradores)
+func (h *KeysHandler) ListKeys(c *gin.Context) {
+	keys, err := h.keyService.ListKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RotateKey gera uma nova chave de assinatura. Com retire_previous, as chaves
+// anteriores são aposentadas na hora, sem período de carência.
+func (h *KeysHandler) RotateKey(c *gin.Context) {
+	var req struct {
+		RetirePrevious bool `json:"retire_previous"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	key, err := h.keyService.Rotate(req.RetirePrevious)
+	if err != nil {
+		h.keyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RetireKey aposenta uma chave imediatamente, invalidando os tokens assinados com ela
+func (h *KeysHandler) RetireKey(c *gin.Context) {
+	if err := h.keyService.Retire(c.Param("kid")); err != nil {
+		h.keyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chave de assinatura aposentada com sucesso"})
+}
+
+// keyError traduz os erros do key ring para respostas HTTP
+func (h *KeysHandler) keyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSigningKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrStaticSigningKey):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
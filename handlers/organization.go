@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OrganizationHandler manipula requisições relacionadas às organizações e seus membros
+type OrganizationHandler struct {
+	organizationService *services.OrganizationService
+}
+
+// NewOrganizationHandler cria uma nova instância do manipulador de organizações
+func NewOrganizationHandler(organizationService *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// ListOrganizations lista as organizações do usuário autenticado
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	organizations, err := h.organizationService.ListOrganizations(c.GetString("userID"), organizationID(c))
+	if err != nil {
+		h.organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+// CreateOrganization cria uma organização administrada pelo usuário autenticado
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req models.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizationService.CreateOrganization(c.GetString("userID"), req)
+	if err != nil {
+		h.organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// GetOrganization retorna a organização ativa
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	organization, err := h.organizationService.GetOrganization(organizationID(c))
+	if err != nil {
+		h.organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// UpdateOrganization atualiza o nome da organização ativa
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	var req models.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizationService.UpdateOrganization(organizationID(c), req)
+	if err != nil {
+		h.organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// SetMemberRoles substitui os papéis de um membro da organização ativa
+func (h *OrganizationHandler) SetMemberRoles(c *gin.Context) {
+	var req struct {
+		RoleIDs []string `json:"role_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.organizationService.SetMemberRoles(organizationID(c), c.Param("userId"), req.RoleIDs)
+	if err != nil {
+		h.organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember remove um membro da organização ativa
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	if err := h.organizationService.RemoveMember(organizationID(c), c.Param("userId")); err != nil {
+		h.organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Membro removido da organização com sucesso"})
+}
+
+// organizationError traduz os erros de organizações para respostas HTTP
+func (h *OrganizationHandler) organizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound), errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMemberExists), errors.Is(err, services.ErrLastOrganizationAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// organizationID retorna a organização ativa do token, ou uuid.Nil quando o
+// usuário não participa de nenhuma organização
+func organizationID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("organizationID"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
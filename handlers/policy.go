@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/policy"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyHandler manipula requisições relacionadas às políticas de acesso baseado em atributos
+type PolicyHandler struct {
+	policyService *services.PolicyService
+}
+
+// NewPolicyHandler cria uma nova instância do manipulador de políticas
+func NewPolicyHandler(policyService *services.PolicyService) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// ListPolicies lista as políticas da organização ativa
+func (h *PolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.policyService.ListPolicies(organizationID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// GetPolicy retorna uma política
+func (h *PolicyHandler) GetPolicy(c *gin.Context) {
+	entry, err := h.policyService.GetPolicy(organizationID(c), c.Param("id"))
+	if err != nil {
+		h.policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// CreatePolicy cria uma nova política
+func (h *PolicyHandler) CreatePolicy(c *gin.Context) {
+	var req models.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.policyService.CreatePolicy(organizationID(c), req)
+	if err != nil {
+		h.policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdatePolicy substitui os dados de uma política
+func (h *PolicyHandler) UpdatePolicy(c *gin.Context) {
+	var req models.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.policyService.UpdatePolicy(organizationID(c), c.Param("id"), req)
+	if err != nil {
+		h.policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeletePolicy exclui uma política
+func (h *PolicyHandler) DeletePolicy(c *gin.Context) {
+	if err := h.policyService.DeletePolicy(organizationID(c), c.Param("id")); err != nil {
+		h.policyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Política excluída com sucesso"})
+}
+
+// UserResource fornece ao PolicyMiddleware os atributos do usuário do parâmetro
+// de rota id, quando ele é o recurso acessado
+func (h *PolicyHandler) UserResource(c *gin.Context) (policy.Attributes, error) {
+	return h.policyService.UserResource(organizationID(c), c.Param("id"))
+}
+
+// policyError traduz os erros de políticas para respostas HTTP
+func (h *PolicyHandler) policyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPolicyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPolicyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
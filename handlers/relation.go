@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/rebac"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RelationHandler manipula as requisições da autorização baseada em relacionamentos
+type RelationHandler struct {
+	relationService *services.RelationService
+}
+
+// NewRelationHandler cria uma nova instância do manipulador de relações
+func NewRelationHandler(relationService *services.RelationService) *RelationHandler {
+	return &RelationHandler{
+		relationService: relationService,
+	}
+}
+
+// Schema retorna os namespaces e as relações configurados
+func (h *RelationHandler) Schema(c *gin.Context) {
+	c.JSON(http.StatusOK, h.relationService.Schema())
+}
+
+// Check responde se o sujeito tem a relação com o objeto
+func (h *RelationHandler) Check(c *gin.Context) {
+	var req models.RelationTupleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allowed, err := h.relationService.Check(req)
+	if err != nil {
+		h.relationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"allowed": allowed})
+}
+
+// Expand retorna a árvore de sujeitos de uma relação
+func (h *RelationHandler) Expand(c *gin.Context) {
+	var req models.RelationExpandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tree, err := h.relationService.Expand(req)
+	if err != nil {
+		h.relationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// ListObjects lista os objetos com os quais o sujeito tem a relação
+func (h *RelationHandler) ListObjects(c *gin.Context) {
+	var req models.RelationListObjectsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objects, err := h.relationService.ListObjects(req)
+	if err != nil {
+		h.relationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"objects": objects})
+}
+
+// ListTuples lista as tuplas armazenadas da organização, filtradas por objeto,
+// relação e sujeito
+func (h *RelationHandler) ListTuples(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Query("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe um organization_id válido"})
+		return
+	}
+
+	tuples, err := h.relationService.ListTuples(orgID, c.Query("object"), c.Query("relation"), c.Query("subject"))
+	if err != nil {
+		h.relationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tuples)
+}
+
+// WriteTuple grava uma tupla
+func (h *RelationHandler) WriteTuple(c *gin.Context) {
+	var req models.RelationTupleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tuple, err := h.relationService.WriteTuple(req)
+	if err != nil {
+		h.relationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tuple)
+}
+
+// DeleteTuple remove uma tupla
+func (h *RelationHandler) DeleteTuple(c *gin.Context) {
+	var req models.RelationTupleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.relationService.DeleteTuple(req); err != nil {
+		h.relationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tupla removida com sucesso"})
+}
+
+// relationError traduz os erros de relações para respostas HTTP
+func (h *RelationHandler) relationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTupleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTuple), errors.Is(err, services.ErrProjectedRelation), errors.Is(err, rebac.ErrUnknownRelation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, rebac.ErrDepthExceeded):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler manipula requisições relacionadas a papéis e ao catálogo de permissões
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+// NewRoleHandler cria uma nova instância do manipulador de papéis
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// ListRoles lista os papéis da organização ativa
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(organizationID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetRole retorna um papel
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleService.GetRole(organizationID(c), c.Param("id"))
+	if err != nil {
+		h.roleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// CreateRole cria um novo papel
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(organizationID(c), req)
+	if err != nil {
+		h.roleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole substitui os dados e as permissões de um papel
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(organizationID(c), c.Param("id"), req)
+	if err != nil {
+		h.roleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole exclui um papel
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(organizationID(c), c.Param("id")); err != nil {
+		h.roleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Papel excluído com sucesso"})
+}
+
+// ListPermissions lista o catálogo de permissões
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleService.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// RegisterPermission registra uma nova permissão no catálogo
+func (h *RoleHandler) RegisterPermission(c *gin.Context) {
+	var req models.PermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permission, err := h.roleService.RegisterPermission(req)
+	if err != nil {
+		h.roleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, permission)
+}
+
+// roleError traduz os erros de papéis e permissões para respostas HTTP
+func (h *RoleHandler) roleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownPermission), errors.Is(err, services.ErrInvalidPermissionName), errors.Is(err, services.ErrRoleCycle),
+		errors.Is(err, services.ErrParentRoleNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrPermissionExists), errors.Is(err, services.ErrSystemRole):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
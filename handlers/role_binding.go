@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleBindingHandler manipula requisições relacionadas às atribuições de papel com escopo
+type RoleBindingHandler struct {
+	bindingService *services.RoleBindingService
+}
+
+// NewRoleBindingHandler cria uma nova instância do manipulador de atribuições de papel com escopo
+func NewRoleBindingHandler(bindingService *services.RoleBindingService) *RoleBindingHandler {
+	return &RoleBindingHandler{
+		bindingService: bindingService,
+	}
+}
+
+// ListBindings lista as atribuições, filtradas opcionalmente por sujeito e recurso
+func (h *RoleBindingHandler) ListBindings(c *gin.Context) {
+	bindings, err := h.bindingService.ListBindings(organizationID(c), models.RoleBindingFilter{
+		SubjectType:  c.Query("subject_type"),
+		SubjectID:    c.Query("subject_id"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, bindings)
+}
+
+// CreateBinding atribui um papel a um usuário ou grupo sobre um recurso
+func (h *RoleBindingHandler) CreateBinding(c *gin.Context) {
+	var req models.RoleBindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	binding, err := h.bindingService.CreateBinding(organizationID(c), req)
+	if err != nil {
+		h.bindingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, binding)
+}
+
+// DeleteBinding exclui uma atribuição
+func (h *RoleBindingHandler) DeleteBinding(c *gin.Context) {
+	if err := h.bindingService.DeleteBinding(organizationID(c), c.Param("id")); err != nil {
+		h.bindingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Atribuição excluída com sucesso"})
+}
+
+// bindingError traduz os erros de atribuições para respostas HTTP
+func (h *RoleBindingHandler) bindingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoleBindingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidSubjectType), errors.Is(err, services.ErrInvalidResource),
+		errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrGroupNotFound), errors.Is(err, services.ErrRoleNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleBindingExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
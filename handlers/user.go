@@ -1,7 +1,8 @@
 package handlers
 
 import (
-	"go-google/models"
+	"errors"
+	"go-google/policy"
 	"go-google/services"
 	"net/http"
 
@@ -28,7 +29,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	profile, err := h.userService.GetUserProfile(userID)
+	profile, err := h.userService.GetUserProfile(userID, organizationID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,9 +38,9 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, profile)
 }
 
-// ListUsers lista todos os usuários (apenas para administradores)
+// ListUsers lista os membros da organização ativa (apenas para administradores)
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	users, err := h.userService.ListUsers()
+	users, err := h.userService.ListUsers(organizationID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,28 +49,41 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, users)
 }
 
-// CreateGroup cria um novo grupo
-func (h *UserHandler) CreateGroup(c *gin.Context) {
-	var req models.GroupRequest
+// AssignUserToGroup atribui um usuário a um grupo
+func (h *UserHandler) AssignUserToGroup(c *gin.Context) {
+	userID := c.Param("id")
+	var req struct {
+		GroupIDs []string `json:"group_ids" binding:"required"`
+	}
+
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	group, err := h.userService.CreateGroup(req)
+	if err := h.userService.AssignUserToGroups(organizationID(c), userID, req.GroupIDs); err != nil {
+		h.userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário atribuído aos grupos com sucesso"})
+}
+
+// GetUser retorna o perfil de um usuário
+func (h *UserHandler) GetUser(c *gin.Context) {
+	user, err := h.userService.GetUser(organizationID(c), c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.userError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, group)
+	c.JSON(http.StatusOK, user)
 }
 
-// AssignUserToGroup atribui um usuário a um grupo
-func (h *UserHandler) AssignUserToGroup(c *gin.Context) {
-	userID := c.Param("id")
+// UpdateUserAttributes substitui os atributos personalizados de um usuário
+func (h *UserHandler) UpdateUserAttributes(c *gin.Context) {
 	var req struct {
-		GroupIDs []string `json:"group_ids" binding:"required"`
+		Attributes policy.Attributes `json:"attributes" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -77,10 +91,24 @@ func (h *UserHandler) AssignUserToGroup(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.AssignUserToGroups(userID, req.GroupIDs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	user, err := h.userService.UpdateUserAttributes(organizationID(c), c.Param("id"), req.Attributes)
+	if err != nil {
+		h.userError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Usuário atribuído aos grupos com sucesso"})
-}
\ No newline at end of file
+	c.JSON(http.StatusOK, user)
+}
+
+// userError traduz os erros de usuários para respostas HTTP
+func (h *UserHandler) userError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrGroupNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
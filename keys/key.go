@@ -0,0 +1,217 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go-google/oidc"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritmos de assinatura suportados para os tokens emitidos pelo serviço
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// rsaKeyBits é o tamanho das chaves RSA geradas
+const rsaKeyBits = 2048
+
+// ErrUnsupportedAlgorithm indica um algoritmo de assinatura não suportado
+var ErrUnsupportedAlgorithm = errors.New("algoritmo de assinatura não suportado")
+
+// Key é uma chave assimétrica de assinatura identificada por kid
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+// Public retorna a chave pública correspondente
+func (k *Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// SigningMethod retorna o método JWT correspondente ao algoritmo da chave
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// Generate cria uma nova chave para o algoritmo informado, com kid igual ao
+// thumbprint da chave pública (RFC 7638)
+func Generate(algorithm string) (*Key, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+
+	switch algorithm {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case ES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave de assinatura: %w", err)
+	}
+
+	return newKey("", algorithm, private)
+}
+
+// ParsePrivateKeyPEM carrega uma chave privada PEM (PKCS#8, PKCS#1 ou SEC 1).
+// Sem kid informado, o thumbprint da chave pública é usado.
+func ParsePrivateKeyPEM(data []byte, kid, algorithm string) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("nenhum bloco PEM encontrado")
+	}
+
+	var (
+		private interface{}
+		err     error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar chave privada: %w", err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tipo de chave privada não suportado")
+	}
+	return newKey(kid, algorithm, signer)
+}
+
+// MarshalPrivateKeyPEM codifica a chave privada em PEM PKCS#8
+func MarshalPrivateKeyPEM(k *Key) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao codificar chave privada: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// newKey valida que o algoritmo corresponde ao tipo da chave e define o kid
+func newKey(kid, algorithm string, private crypto.Signer) (*Key, error) {
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != RS256 {
+			return nil, fmt.Errorf("chave RSA incompatível com o algoritmo %s", algorithm)
+		}
+	case *ecdsa.PrivateKey:
+		if algorithm != ES256 || key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("chave EC incompatível com o algoritmo %s", algorithm)
+		}
+	case ed25519.PrivateKey:
+		if algorithm != EdDSA {
+			return nil, fmt.Errorf("chave Ed25519 incompatível com o algoritmo %s", algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, private)
+	}
+
+	k := &Key{ID: kid, Algorithm: algorithm, Private: private}
+	if k.ID == "" {
+		thumbprint, err := k.Thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		k.ID = thumbprint
+	}
+	return k, nil
+}
+
+// JWK retorna a chave pública no formato publicado no JWKS
+func (k *Key) JWK() oidc.JSONWebKey {
+	jwk := oidc.JSONWebKey{
+		KeyID:     k.ID,
+		Algorithm: k.Algorithm,
+		Use:       "sig",
+	}
+
+	switch public := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeSegment(public.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// As coordenadas têm tamanho fixo para a curva (RFC 7518, seção 6.2.1)
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = public.Curve.Params().Name
+		jwk.X = encodeSegment(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeSegment(public.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeSegment(public)
+	}
+	return jwk
+}
+
+// Thumbprint calcula o thumbprint SHA-256 da chave pública (RFC 7638)
+func (k *Key) Thumbprint() (string, error) {
+	jwk := k.JWK()
+
+	// Apenas os membros obrigatórios, em ordem lexicográfica
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	default:
+		return "", ErrUnsupportedAlgorithm
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// encodeSegment codifica bytes em base64url sem padding, como exigido em JWK
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,69 @@
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrUndecryptable indica que nenhum dos segredos informados decifra a chave
+var ErrUndecryptable = errors.New("não foi possível decifrar a chave: o segredo de cifragem mudou?")
+
+// Seal cifra a chave privada com AES-256-GCM para armazenamento no banco,
+// usando uma chave derivada do segredo informado
+func Seal(plaintext []byte, secret string) (string, error) {
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decifra um valor produzido por Seal, tentando cada segredo informado
+// na ordem (o atual e os anteriores, durante a troca do segredo)
+func Open(ciphertext string, secrets ...string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("chave cifrada inválida: %w", err)
+	}
+
+	for _, secret := range secrets {
+		aead, err := newAEAD(secret)
+		if err != nil {
+			return nil, err
+		}
+		if len(sealed) < aead.NonceSize() {
+			return nil, errors.New("chave cifrada inválida")
+		}
+
+		nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, data, nil); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrUndecryptable
+}
+
+// newAEAD cria a cifra AES-256-GCM a partir do segredo
+func newAEAD(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, errors.New("segredo para cifrar as chaves de assinatura não configurado")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,25 @@
+package keys
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOpenWithPreviousSecret(t *testing.T) {
+	sealed, err := Seal([]byte("chave privada"), "antigo")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	plaintext, err := Open(sealed, "novo", "antigo")
+	if err != nil {
+		t.Fatalf("Open com o segredo anterior: %v", err)
+	}
+	if string(plaintext) != "chave privada" {
+		t.Errorf("Open = %q, esperado %q", plaintext, "chave privada")
+	}
+
+	if _, err := Open(sealed, "novo"); !errors.Is(err, ErrUndecryptable) {
+		t.Errorf("Open sem o segredo anterior = %v, esperado ErrUndecryptable", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package mailer envia os e-mails do serviço (como os convites) por um Sender
+// plugável: SMTP em produção ou arquivos .eml em um diretório local durante o
+// desenvolvimento.
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"go-google/config"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Transportes aceitos em MAIL_SENDER
+const (
+	TransportFile = "file"
+	TransportSMTP = "smtp"
+)
+
+// defaultFileDir é o diretório dos e-mails gravados quando MAIL_FILE_DIR não é informado
+const defaultFileDir = "mail"
+
+// Message é um e-mail de texto simples
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender entrega mensagens de e-mail
+type Sender interface {
+	Send(message Message) error
+}
+
+// FromConfig cria o Sender configurado em MAIL_SENDER. Sem configuração, as
+// mensagens são gravadas como arquivos em MAIL_FILE_DIR.
+func FromConfig(cfg *config.Config) (Sender, error) {
+	switch cfg.MailSender {
+	case "", TransportFile:
+		dir := cfg.MailFileDir
+		if dir == "" {
+			dir = defaultFileDir
+		}
+		return NewFileSender(dir, cfg.MailFrom), nil
+	case TransportSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST é obrigatório com MAIL_SENDER=smtp")
+		}
+		return NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("MAIL_SENDER desconhecido %q: use file ou smtp", cfg.MailSender)
+	}
+}
+
+// FileSender grava cada mensagem como um arquivo .eml, substituindo o envio
+// real em ambientes locais
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender cria um Sender que grava as mensagens no diretório informado
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+// Send grava a mensagem em um novo arquivo do diretório
+func (s *FileSender) Send(message Message) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("erro ao criar o diretório de e-mails: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+
+	return os.WriteFile(filepath.Join(s.dir, name), compose(s.from, message), 0o600)
+}
+
+// SMTPSender entrega as mensagens por um servidor SMTP, autenticando-se com
+// PLAIN quando o usuário é informado
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender cria um Sender SMTP; a porta padrão é 587
+func NewSMTPSender(host, port, username, password, from string) *SMTPSender {
+	if port == "" {
+		port = "587"
+	}
+	return &SMTPSender{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send entrega a mensagem ao servidor SMTP
+func (s *SMTPSender) Send(message Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	return smtp.SendMail(s.addr, auth, s.from, []string{message.To}, compose(s.from, message))
+}
+
+// compose monta a mensagem no formato RFC 5322, com o assunto codificado para
+// aceitar acentos
+func compose(from string, message Message) []byte {
+	var buf bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&buf, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
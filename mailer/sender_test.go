@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := NewFileSender(dir, "noreply@example.com")
+
+	err := sender.Send(Message{
+		To:      "ana@example.com",
+		Subject: "Convite para a Organização",
+		Body:    "Olá!\nAcesse o link.",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(files) != 1 || filepath.Ext(files[0].Name()) != ".eml" {
+		t.Fatalf("arquivos gravados = %v, esperado um .eml", files)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, want := range []string{
+		"From: noreply@example.com\r\n",
+		"To: ana@example.com\r\n",
+		"Subject: =?utf-8?q?Convite_para_a_Organiza=C3=A7=C3=A3o?=\r\n",
+		"\r\n\r\nOlá!\r\nAcesse o link.",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("mensagem sem %q:\n%s", want, content)
+		}
+	}
+}
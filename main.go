@@ -3,8 +3,11 @@ package main
 import (
 	"go-google/config"
 	"go-google/handlers"
+	"go-google/mailer"
 	"go-google/middleware"
 	"go-google/models"
+	"go-google/providers"
+	"go-google/rebac"
 	"go-google/repository"
 	"go-google/services"
 	"log"
@@ -27,57 +30,260 @@ func main() {
 	}
 
 	// Auto-migrar modelos
-	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.Role{})
+	err = db.AutoMigrate(&models.Organization{}, &models.User{}, &models.Membership{}, &models.UserIdentity{}, &models.Group{}, &models.Role{}, &models.LoginState{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.SigningKey{}, &models.LoginHandoff{}, &models.Permission{}, &models.RoleBinding{}, &models.Policy{}, &models.RelationTuple{}, &models.Invitation{})
 	if err != nil {
 		log.Fatalf("Erro ao migrar banco de dados: %v", err)
 	}
 
 	// Inicializar repositórios
 	userRepo := repository.NewUserRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
 	groupRepo := repository.NewGroupRepository(db)
+	loginStateRepo := repository.NewLoginStateRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	revocationRepo := repository.NewRevocationRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	loginHandoffRepo := repository.NewLoginHandoffRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	roleBindingRepo := repository.NewRoleBindingRepository(db)
+	policyRepo := repository.NewPolicyRepository(db)
+	relationTupleRepo := repository.NewRelationTupleRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+
+	// Migrar a antiga coluna google_id para as identidades vinculadas
+	if err := identityRepo.MigrateLegacyGoogleIDs(); err != nil {
+		log.Fatalf("Erro ao migrar identidades do Google: %v", err)
+	}
+
+	// Mover os dados anteriores às organizações para a organização padrão
+	if err := organizationRepo.MigrateLegacyData(); err != nil {
+		log.Fatalf("Erro ao migrar dados para a organização padrão: %v", err)
+	}
+
+	// Separar por organização as tuplas de relacionamento gravadas antes delas
+	if err := relationTupleRepo.MigrateOrganizationScope(); err != nil {
+		log.Fatalf("Erro ao migrar as tuplas de relacionamento: %v", err)
+	}
+
+	// Carregar o schema das relações (namespaces e userset rewrites)
+	relationSchema, err := rebac.LoadSchema(cfg.RelationSchemaFile)
+	if err != nil {
+		log.Fatalf("Erro ao carregar o schema de relações: %v", err)
+	}
+
+	// Registrar provedores de identidade
+	providerRegistry := providers.FromConfig(cfg)
+
+	// Configurar o envio de e-mails (arquivos locais ou SMTP)
+	mailSender, err := mailer.FromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Erro ao configurar o envio de e-mails: %v", err)
+	}
 
 	// Inicializar serviços
-	authService := services.NewAuthService(cfg, userRepo, groupRepo)
-	userService := services.NewUserService(userRepo, groupRepo)
+	revocationService := services.NewRevocationService(revocationRepo)
+	keyService := services.NewKeyService(cfg, signingKeyRepo)
+	if err := keyService.Load(); err != nil {
+		log.Fatalf("Erro ao carregar chaves de assinatura: %v", err)
+	}
+	keyService.Start()
+	accessResolver := services.NewAccessResolver(roleRepo, groupRepo, roleBindingRepo)
+	authzService := services.NewAuthzService(userRepo, accessResolver)
+	organizationService := services.NewOrganizationService(organizationRepo, userRepo, roleRepo, authzService, accessResolver)
+	invitationService := services.NewInvitationService(cfg, invitationRepo, groupRepo, userRepo, organizationService, mailSender, authzService)
+	authService := services.NewAuthService(cfg, userRepo, organizationService, invitationService, loginStateRepo, identityRepo, refreshTokenRepo, loginHandoffRepo, revocationService, keyService, providerRegistry, accessResolver)
+	userService := services.NewUserService(userRepo, groupRepo, authzService, accessResolver)
+	roleService := services.NewRoleService(roleRepo, permissionRepo, authzService)
+	groupService := services.NewGroupService(groupRepo, roleRepo, userRepo, authzService, accessResolver)
+	roleBindingService := services.NewRoleBindingService(roleBindingRepo, roleRepo, groupRepo, userRepo)
+	policyService := services.NewPolicyService(policyRepo, userRepo, authzService)
+	authzService.UsePolicies(policyService)
+	relationService := services.NewRelationService(relationTupleRepo, groupRepo, relationSchema)
+	explainService := services.NewExplainService(userRepo, groupRepo, roleRepo, accessResolver)
+	if err := roleService.SeedCatalog(); err != nil {
+		log.Fatalf("Erro ao registrar o catálogo de permissões: %v", err)
+	}
 
 	// Inicializar handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
+	identityHandler := handlers.NewIdentityHandler(authService)
+	keysHandler := handlers.NewKeysHandler(keyService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	groupHandler := handlers.NewGroupHandler(groupService)
+	roleBindingHandler := handlers.NewRoleBindingHandler(roleBindingService)
+	authzHandler := handlers.NewAuthzHandler(authzService)
+	policyHandler := handlers.NewPolicyHandler(policyService)
+	relationHandler := handlers.NewRelationHandler(relationService)
+	explainHandler := handlers.NewExplainHandler(explainService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
 
 	// Configurar router
 	router := gin.Default()
 
+	// O IP do cliente (context.ip das políticas e sessões) só é lido de
+	// X-Forwarded-For quando a conexão vem de um proxy confiável; sem
+	// TRUSTED_PROXIES, vale o endereço da conexão
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES inválido: %v", err)
+	}
+
 	// Configurar CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.CSRFHeaderName},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
+	// No modo de sessão por cookies, o middleware também aceita o cookie de
+	// acesso e valida o token CSRF
+	var csrfVerifier middleware.CSRFVerifier
+	if cfg.CookieSessions() {
+		csrfVerifier = authService
+	}
+
+	// Na autorização em tempo real, papéis e permissões vêm do banco em vez do token
+	var authzResolver middleware.AuthorizationResolver
+	if cfg.LiveAuthz() {
+		authzResolver = authzService
+	}
+	authMiddleware := middleware.AuthMiddleware(keyService.Keyfunc, revocationService, csrfVerifier, authzResolver)
+
 	// Rotas de autenticação (públicas)
 	auth := router.Group("/auth")
 	{
+		auth.GET("/providers", authHandler.ListProviders)
 		auth.GET("/login", authHandler.GoogleLogin)
 		auth.GET("/callback", authHandler.GoogleCallback)
+		auth.GET("/:provider/login", authHandler.Login)
+		auth.GET("/:provider/callback", authHandler.Callback)
+		auth.POST("/exchange", authHandler.Exchange)
 		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/switch-organization", authHandler.SwitchOrganization)
+		auth.POST("/logout", authMiddleware, authHandler.Logout)
+		auth.POST("/logout-all", authMiddleware, authHandler.LogoutAll)
+	}
+
+	// Chaves públicas para verificação dos tokens emitidos
+	router.GET("/.well-known/jwks.json", keysHandler.JWKS)
+
+	// Revogação de tokens (RFC 7009)
+	router.POST("/oauth/revoke", authHandler.RevokeToken)
+
+	// Verificações de autorização para outros serviços, autenticados com as
+	// próprias credenciais (AUTHZ_SERVICE_CLIENTS) em vez do token de um usuário
+	authz := router.Group("/api/authz")
+	authz.Use(middleware.ServiceAuthMiddleware(cfg.ServiceClients))
+	{
+		authz.POST("/check", authzHandler.Check)
+		authz.POST("/check-many", authzHandler.CheckMany)
+
+		// Autorização baseada em relacionamentos (tuplas objeto#relação@sujeito)
+		authz.GET("/relations/schema", relationHandler.Schema)
+		authz.POST("/relations/check", relationHandler.Check)
+		authz.POST("/relations/expand", relationHandler.Expand)
+		authz.POST("/relations/list-objects", relationHandler.ListObjects)
+		authz.GET("/relations/tuples", relationHandler.ListTuples)
+		authz.POST("/relations/tuples", relationHandler.WriteTuple)
+		authz.DELETE("/relations/tuples", relationHandler.DeleteTuple)
 	}
 
 	// Rotas protegidas (requerem autenticação)
 	api := router.Group("/api")
-	api.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+	api.Use(authMiddleware)
 	{
 		// Rotas de usuário
 		api.GET("/profile", userHandler.GetProfile)
-		
-		// Rotas administrativas (requerem role específica)
+
+		// Identidades externas vinculadas à conta
+		api.GET("/identities", identityHandler.ListIdentities)
+		api.POST("/identities/:provider/link", identityHandler.LinkIdentity)
+		api.DELETE("/identities/:id", identityHandler.UnlinkIdentity)
+
+		// Organizações do usuário; a troca da organização ativa é feita em
+		// /auth/switch-organization
+		api.GET("/organizations", organizationHandler.ListOrganizations)
+		api.POST("/organizations", organizationHandler.CreateOrganization)
+
+		// Consulta de um usuário sujeita às políticas de acesso (por exemplo,
+		// suporte lendo apenas usuários da própria região)
+		api.GET("/users/:id", middleware.PolicyMiddleware(policyService, "users:read", policyHandler.UserResource), userHandler.GetUser)
+
+		// Gestão de um grupo específico por quem tem a permissão global ou um
+		// papel atribuído sobre o grupo. A alteração de membros exige que o
+		// solicitante já tenha os papéis concedidos pelo grupo.
+		api.GET("/groups/:id", middleware.RequirePermissionOn(authzService, "groups:read", models.ResourceTypeGroup, "id"), groupHandler.GetGroup)
+		api.GET("/groups/:id/members", middleware.RequirePermissionOn(authzService, "groups:read", models.ResourceTypeGroup, "id"), groupHandler.ListMembers)
+		api.POST("/groups/:id/members/:userId", middleware.RequirePermissionOn(authzService, "groups:write", models.ResourceTypeGroup, "id"), groupHandler.DelegatedAddMember)
+		api.DELETE("/groups/:id/members/:userId", middleware.RequirePermissionOn(authzService, "groups:write", models.ResourceTypeGroup, "id"), groupHandler.DelegatedRemoveMember)
+
+		// Rotas que afetam todas as organizações
+		platform := api.Group("/admin")
+		platform.Use(middleware.PlatformAdminMiddleware(cfg.PlatformAdmins))
+		{
+			// Catálogo de permissões
+			platform.POST("/permissions", roleHandler.RegisterPermission)
+
+			// Key ring de assinatura dos tokens
+			platform.GET("/keys", keysHandler.ListKeys)
+			platform.POST("/keys/rotate", keysHandler.RotateKey)
+			platform.POST("/keys/:kid/retire", keysHandler.RetireKey)
+		}
+
+		// Rotas administrativas da organização ativa (requerem role específica)
 		admin := api.Group("/admin")
 		admin.Use(middleware.RoleMiddleware("admin"))
 		{
+			// Organização ativa e seus membros
+			admin.GET("/organization", organizationHandler.GetOrganization)
+			admin.PUT("/organization", organizationHandler.UpdateOrganization)
+			admin.PUT("/members/:userId/roles", organizationHandler.SetMemberRoles)
+			admin.DELETE("/members/:userId", organizationHandler.RemoveMember)
+			admin.GET("/invitations", invitationHandler.ListInvitations)
+			admin.POST("/invitations", invitationHandler.CreateInvitation)
+			admin.POST("/invitations/:id/resend", invitationHandler.ResendInvitation)
+			admin.DELETE("/invitations/:id", invitationHandler.RevokeInvitation)
+
 			admin.GET("/users", userHandler.ListUsers)
-			admin.POST("/groups", userHandler.CreateGroup)
 			admin.PUT("/users/:id/groups", userHandler.AssignUserToGroup)
+			admin.PUT("/users/:id/attributes", userHandler.UpdateUserAttributes)
+			admin.GET("/users/:id/effective-permissions", explainHandler.EffectivePermissions)
+
+			// Grupos e membros
+			admin.GET("/groups", groupHandler.ListGroups)
+			admin.POST("/groups", groupHandler.CreateGroup)
+			admin.GET("/groups/:id", groupHandler.GetGroup)
+			admin.PUT("/groups/:id", groupHandler.UpdateGroup)
+			admin.DELETE("/groups/:id", groupHandler.DeleteGroup)
+			admin.PUT("/groups/:id/roles", groupHandler.AssignRoles)
+			admin.GET("/groups/:id/members", groupHandler.ListMembers)
+			admin.POST("/groups/:id/members/:userId", groupHandler.AddMember)
+			admin.DELETE("/groups/:id/members/:userId", groupHandler.RemoveMember)
+
+			// Papéis e catálogo de permissões
+			admin.GET("/roles", roleHandler.ListRoles)
+			admin.POST("/roles", roleHandler.CreateRole)
+			admin.GET("/roles/:id", roleHandler.GetRole)
+			admin.PUT("/roles/:id", roleHandler.UpdateRole)
+			admin.DELETE("/roles/:id", roleHandler.DeleteRole)
+			admin.GET("/permissions", roleHandler.ListPermissions)
+
+			// Atribuições de papel com escopo em um recurso
+			admin.GET("/bindings", roleBindingHandler.ListBindings)
+			admin.POST("/bindings", roleBindingHandler.CreateBinding)
+			admin.DELETE("/bindings/:id", roleBindingHandler.DeleteBinding)
+
+			// Políticas de acesso baseado em atributos
+			admin.GET("/policies", policyHandler.ListPolicies)
+			admin.POST("/policies", policyHandler.CreatePolicy)
+			admin.GET("/policies/:id", policyHandler.GetPolicy)
+			admin.PUT("/policies/:id", policyHandler.UpdatePolicy)
+			admin.DELETE("/policies/:id", policyHandler.DeletePolicy)
 		}
 	}
 
@@ -86,4 +292,4 @@ func main() {
 	if err := router.Run(":" + cfg.ServerPort); err != nil {
 		log.Fatalf("Erro ao iniciar servidor: %v", err)
 	}
-}
\ No newline at end of file
+}
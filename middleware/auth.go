@@ -1,31 +1,68 @@
 package middleware
 
 import (
-	"errors"
+	"crypto/subtle"
+	"go-google/permission"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware verifica se o usuário está autenticado
-func AuthMiddleware(secretKey string) gin.HandlerFunc {
+// Nomes dos cookies e do cabeçalho usados no modo de sessão por cookies
+const (
+	// AccessCookieName é o cookie HttpOnly com o token de acesso
+	AccessCookieName = "access_token"
+	// CSRFCookieName é o cookie legível pelo frontend com o token CSRF da sessão
+	CSRFCookieName = "csrf_token"
+	// CSRFHeaderName é o cabeçalho em que o frontend reenvia o token CSRF
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// RevocationChecker consulta se um token de acesso foi revogado antes da expiração
+type RevocationChecker interface {
+	IsRevoked(tokenID, sessionID, userID string, issuedAt time.Time) (bool, error)
+}
+
+// CSRFVerifier verifica se um token CSRF foi emitido para a sessão informada
+type CSRFVerifier interface {
+	VerifyCSRFToken(token, sessionID string) bool
+}
+
+// AuthorizationResolver resolve os papéis e permissões atuais de um usuário na organização
+type AuthorizationResolver interface {
+	Resolve(userID, organizationID string) (roles []string, permissions []string, err error)
+}
+
+// ScopedAuthorizer verifica as permissões concedidas por atribuições de papel
+// com escopo em um recurso específico
+type ScopedAuthorizer interface {
+	AllowedOn(userID, organizationID, requiredPermission, resourceType, resourceID string) (bool, error)
+}
+
+// AuthMiddleware verifica se o usuário está autenticado e se o token não foi revogado.
+// keyfunc resolve a chave pública de verificação pelo kid do token. Quando csrf é
+// informado, o token de acesso também é aceito pelo cookie de sessão, e as
+// requisições que alteram estado autenticadas por cookie exigem o token CSRF.
+// Quando authz é informado, papéis e permissões vêm dele em vez das claims do token.
+func AuthMiddleware(keyfunc jwt.Keyfunc, revocations RevocationChecker, csrf CSRFVerifier, authz AuthorizationResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		tokenString, fromCookie := "", false
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			tokenString = strings.Replace(authHeader, "Bearer ", "", 1)
+		} else if csrf != nil {
+			tokenString, _ = c.Cookie(AccessCookieName)
+			fromCookie = tokenString != ""
+		}
+		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token de autenticação ausente"})
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("método de assinatura inválido")
-			}
-			return []byte(secretKey), nil
-		})
+		token, err := jwt.Parse(tokenString, keyfunc)
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
@@ -40,6 +77,14 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 			return
 		}
 
+		// Tokens de atualização têm as mesmas claims de sessão e vida longa: só o
+		// token de acesso autentica as rotas
+		if tokenType, _ := claims["type"].(string); tokenType != "access" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+			c.Abort()
+			return
+		}
+
 		// Extrair dados do token
 		userID, ok := claims["sub"].(string)
 		if !ok {
@@ -48,31 +93,104 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 			return
 		}
 
+		// Verificar revogação (logout, logout-all ou RFC 7009)
+		tokenID, _ := claims["jti"].(string)
+		sessionID, _ := claims["sid"].(string)
+		var issuedAt time.Time
+		if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+			issuedAt = iat.Time
+		}
+		revoked, err := revocations.IsRevoked(tokenID, sessionID, userID, issuedAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar revogação do token"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token revogado"})
+			c.Abort()
+			return
+		}
+
+		// Cookies são enviados automaticamente pelo navegador, então requisições
+		// que alteram estado precisam comprovar o token CSRF (double-submit)
+		if fromCookie && !isSafeMethod(c.Request.Method) && !ValidCSRF(c, csrf, sessionID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token CSRF inválido", "code": "csrf_invalid"})
+			c.Abort()
+			return
+		}
+
 		// Armazenar dados do usuário no contexto
+		email, _ := claims["email"].(string)
 		c.Set("userID", userID)
-		
-		// Extrair roles do token
-		if roles, ok := claims["roles"].([]interface{}); ok {
-			roleList := make([]string, len(roles))
-			for i, role := range roles {
-				roleList[i] = role.(string)
-			}
-			c.Set("roles", roleList)
-		}
-		
-		// Extrair permissões do token
-		if permissions, ok := claims["permissions"].([]interface{}); ok {
-			permList := make([]string, len(permissions))
-			for i, perm := range permissions {
-				permList[i] = perm.(string)
+		c.Set("email", email)
+		c.Set("tokenID", tokenID)
+		c.Set("sessionID", sessionID)
+
+		// Organização ativa da sessão, que delimita papéis, grupos e recursos
+		organizationID, _ := claims["org_id"].(string)
+		c.Set("organizationID", organizationID)
+
+		// Instante do último login no provedor, usado para exigir reautenticação
+		if authTime, ok := claims["auth_time"].(float64); ok {
+			c.Set("authTime", int64(authTime))
+		}
+
+		// Resolver papéis e permissões atuais no banco (autorização em tempo real)
+		// ou extraí-los do token
+		if authz != nil {
+			roles, permissions, err := authz.Resolve(userID, organizationID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao resolver permissões do usuário"})
+				c.Abort()
+				return
 			}
-			c.Set("permissions", permList)
+			c.Set("roles", roles)
+			c.Set("permissions", permissions)
+		} else {
+			setClaimList(c, claims, "roles")
+			setClaimList(c, claims, "permissions")
 		}
 
 		c.Next()
 	}
 }
 
+// setClaimList copia para o contexto uma claim do token que contém uma lista de textos
+func setClaimList(c *gin.Context, claims jwt.MapClaims, name string) {
+	values, ok := claims[name].([]interface{})
+	if !ok {
+		return
+	}
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			list = append(list, text)
+		}
+	}
+	c.Set(name, list)
+}
+
+// ValidCSRF confere se o cabeçalho CSRF é igual ao cookie CSRF e se o token
+// foi emitido para a sessão informada
+func ValidCSRF(c *gin.Context, csrf CSRFVerifier, sessionID string) bool {
+	header := c.GetHeader(CSRFHeaderName)
+	cookie, _ := c.Cookie(CSRFCookieName)
+	if header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(cookie)) != 1 {
+		return false
+	}
+	return csrf.VerifyCSRFToken(header, sessionID)
+}
+
+// isSafeMethod indica se o método HTTP não altera estado
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
 // RoleMiddleware verifica se o usuário tem um papel específico
 func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -108,6 +226,23 @@ func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 	}
 }
 
+// PlatformAdminMiddleware restringe as rotas que afetam todas as organizações
+// (chaves de assinatura e catálogo de permissões) aos e-mails informados
+func PlatformAdminMiddleware(emails []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := c.GetString("email")
+		for _, allowed := range emails {
+			if email != "" && strings.EqualFold(email, allowed) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado: restrito aos administradores da plataforma"})
+		c.Abort()
+	}
+}
+
 // PermissionMiddleware verifica se o usuário tem uma permissão específica
 func PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -125,15 +260,44 @@ func PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
 			return
 		}
 
-		hasPermission := false
-		for _, perm := range permList {
-			if perm == requiredPermission {
-				hasPermission = true
-				break
+		// Curingas e ações implícitas são considerados (users:* e users:write cobrem users:read)
+		if !permission.Allowed(permList, requiredPermission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado: permissão necessária não encontrada"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermissionOn exige a permissão globalmente ou sobre o recurso do tipo
+// informado cujo ID está no parâmetro de rota resourceParam, concedida por uma
+// atribuição de papel com escopo (por exemplo, admin apenas de um grupo)
+func RequirePermissionOn(authorizer ScopedAuthorizer, requiredPermission, resourceType, resourceParam string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Quem tem a permissão global não depende das atribuições com escopo
+		if permissions, ok := c.Get("permissions"); ok {
+			if permList, ok := permissions.([]string); ok && permission.Allowed(permList, requiredPermission) {
+				c.Next()
+				return
 			}
 		}
 
-		if !hasPermission {
+		resourceID := c.Param(resourceParam)
+		if resourceID == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado: recurso não informado"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := authorizer.AllowedOn(c.GetString("userID"), c.GetString("organizationID"), requiredPermission, resourceType, resourceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar permissões"})
+			c.Abort()
+			return
+		}
+		if !allowed {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado: permissão necessária não encontrada"})
 			c.Abort()
 			return
@@ -141,4 +305,4 @@ func PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
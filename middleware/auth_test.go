@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("segredo-de-teste")
+
+// noRevocations não revoga nenhum token
+type noRevocations struct{}
+
+func (noRevocations) IsRevoked(tokenID, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	return false, nil
+}
+
+// fixedAuthz concede os mesmos papéis e permissões a qualquer usuário
+type fixedAuthz struct{}
+
+func (fixedAuthz) Resolve(userID, organizationID string) ([]string, []string, error) {
+	return []string{"admin"}, []string{"users:*"}, nil
+}
+
+func testKeyfunc(token *jwt.Token) (interface{}, error) {
+	return testSecret, nil
+}
+
+func signTestToken(t *testing.T, tokenType string) string {
+	t.Helper()
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub": "user-1",
+		"jti": "token-1",
+		"sid": "session-1",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	if tokenType != "" {
+		claims["type"] = tokenType
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testSecret)
+	if err != nil {
+		t.Fatalf("erro ao assinar token: %v", err)
+	}
+	return signed
+}
+
+func TestAuthMiddlewareAcceptsOnlyAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/profile", AuthMiddleware(testKeyfunc, noRevocations{}, nil, fixedAuthz{}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		tokenType string
+		want      int
+	}{
+		{"token de acesso", "access", http.StatusOK},
+		{"token de atualização", "refresh", http.StatusUnauthorized},
+		{"sem tipo", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+			req.Header.Set("Authorization", "Bearer "+signTestToken(t, tt.tokenType))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, esperado %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"go-google/permission"
+	"go-google/policy"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyEvaluator avalia as políticas de acesso baseado em atributos da organização
+type PolicyEvaluator interface {
+	Evaluate(userID, organizationID, action string, resource, context policy.Attributes) (policy.Decision, error)
+}
+
+// ResourceAttributes extrai da requisição os atributos do recurso acessado
+type ResourceAttributes func(c *gin.Context) (policy.Attributes, error)
+
+// PolicyMiddleware avalia as políticas para a ação com os atributos do usuário,
+// do recurso (resource pode ser nil) e do contexto da requisição (context.time,
+// context.ip, context.method e context.path). Uma política de negação bloqueia
+// o acesso mesmo com a permissão nos papéis; uma política de concessão libera o
+// acesso sem ela; sem políticas aplicáveis, vale a permissão dos papéis, como
+// no PermissionMiddleware. O ID da política que decidiu fica em "policyID".
+func PolicyMiddleware(evaluator PolicyEvaluator, action string, resource ResourceAttributes) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resourceAttributes := policy.Attributes{}
+		if resource != nil {
+			attributes, err := resource(c)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao carregar os atributos do recurso"})
+				c.Abort()
+				return
+			}
+			if attributes != nil {
+				resourceAttributes = attributes
+			}
+		}
+
+		context := policy.Attributes{
+			"time":   time.Now(),
+			"ip":     c.ClientIP(),
+			"method": c.Request.Method,
+			"path":   c.FullPath(),
+		}
+
+		decision, err := evaluator.Evaluate(c.GetString("userID"), c.GetString("organizationID"), action, resourceAttributes, context)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao avaliar as políticas de acesso"})
+			c.Abort()
+			return
+		}
+		if decision.PolicyID != "" {
+			c.Set("policyID", decision.PolicyID)
+		}
+
+		switch {
+		case decision.Denied():
+			c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado por política", "policy_id": decision.PolicyID})
+			c.Abort()
+			return
+		case decision.Allowed():
+			c.Next()
+			return
+		}
+
+		var permList []string
+		if permissions, ok := c.Get("permissions"); ok {
+			permList, _ = permissions.([]string)
+		}
+		if !permission.Allowed(permList, action) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado: permissão necessária não encontrada"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuthMiddleware autentica os serviços que chamam a API com as próprias
+// credenciais (HTTP Basic com id e segredo), e não com o token de um usuário.
+// Sem clientes configurados, todas as chamadas são rejeitadas.
+func ServiceAuthMiddleware(clients map[string]string) gin.HandlerFunc {
+	// Os segredos são comparados pelo hash, em tempo constante e com tamanho fixo
+	hashes := make(map[string][sha256.Size]byte, len(clients))
+	for id, secret := range clients {
+		hashes[id] = sha256.Sum256([]byte(secret))
+	}
+
+	return func(c *gin.Context) {
+		clientID, secret, ok := c.Request.BasicAuth()
+		expected, known := hashes[clientID]
+		provided := sha256.Sum256([]byte(secret))
+		if !ok || !known || subtle.ConstantTimeCompare(provided[:], expected[:]) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="authz"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciais de serviço inválidas"})
+			c.Abort()
+			return
+		}
+
+		c.Set("serviceClient", clientID)
+		c.Next()
+	}
+}
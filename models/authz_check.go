@@ -0,0 +1,59 @@
+package models
+
+import "go-google/policy"
+
+// Motivos das decisões de autorização retornadas aos serviços
+const (
+	// DecisionGlobalPermission indica que a permissão foi concedida pelos papéis globais
+	DecisionGlobalPermission = "global_permission"
+	// DecisionPolicy indica que a permissão foi concedida por uma política de acesso
+	DecisionPolicy = "policy"
+	// DecisionRoleBinding indica que a permissão ou o papel vem de uma atribuição com escopo no recurso
+	DecisionRoleBinding = "role_binding"
+	// DecisionRole indica que o usuário tem o papel, direto, por grupo ou herdado
+	DecisionRole = "role"
+	// DecisionGroupMember indica que o usuário é membro do grupo ou de um subgrupo dele
+	DecisionGroupMember = "group_member"
+	// DecisionUserNotFound indica que o usuário não existe
+	DecisionUserNotFound = "user_not_found"
+	// DecisionNotOrganizationMember indica que o usuário não participa da organização
+	DecisionNotOrganizationMember = "not_organization_member"
+	// DecisionMissingPermission indica que nenhum papel concede a permissão
+	DecisionMissingPermission = "missing_permission"
+	// DecisionPolicyDenied indica que uma política de acesso negou a permissão
+	DecisionPolicyDenied = "policy_denied"
+	// DecisionMissingRole indica que o usuário não tem o papel
+	DecisionMissingRole = "missing_role"
+	// DecisionNotGroupMember indica que o usuário não é membro do grupo
+	DecisionNotGroupMember = "not_group_member"
+)
+
+// AuthzCheckRequest pergunta se um usuário tem, na organização, a permissão, o
+// papel e a associação ao grupo informados; todos os critérios informados
+// precisam ser atendidos. Com resource_type e resource_id, as atribuições de
+// papel com escopo no recurso também são consideradas. A permissão é avaliada
+// também pelas políticas de acesso da organização, com os atributos do recurso
+// em resource e do contexto da requisição em context.
+type AuthzCheckRequest struct {
+	UserID         string            `json:"user_id" binding:"required"`
+	OrganizationID string            `json:"organization_id" binding:"required,uuid"`
+	Permission     string            `json:"permission"`
+	Role           string            `json:"role"`
+	Group          string            `json:"group"`
+	ResourceType   string            `json:"resource_type"`
+	ResourceID     string            `json:"resource_id"`
+	Resource       policy.Attributes `json:"resource"`
+	Context        policy.Attributes `json:"context"`
+}
+
+// AuthzCheckManyRequest agrupa várias verificações em uma única chamada
+type AuthzCheckManyRequest struct {
+	Checks []AuthzCheckRequest `json:"checks" binding:"required,max=100,dive"`
+}
+
+// AuthzDecision é o resultado de uma verificação de autorização
+type AuthzDecision struct {
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason"`
+	PolicyID string `json:"policy_id,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "github.com/google/uuid"
+
+// Tipos dos passos de um caminho de concessão
+const (
+	GrantStepGroup = "group"
+	GrantStepRole  = "role"
+)
+
+// GrantStep é um grupo ou papel no caminho que leva do usuário a uma permissão
+type GrantStep struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// PermissionGrant é um caminho que concede a permissão: do primeiro passo (papel
+// direto ou grupo direto do usuário) até o papel que declara Granted, que pode
+// ser um curinga ou uma ação que implica a permissão
+type PermissionGrant struct {
+	Granted string      `json:"granted"`
+	Path    []GrantStep `json:"path"`
+}
+
+// EffectivePermission é uma permissão efetiva e, no modo explain, os caminhos que a concedem
+type EffectivePermission struct {
+	Permission string            `json:"permission"`
+	GrantedBy  []PermissionGrant `json:"granted_by,omitempty"`
+}
+
+// PermissionSimulation descreve o que mudaria nos papéis e permissões do usuário
+// com as alterações de grupos simuladas
+type PermissionSimulation struct {
+	AddGroups          []string `json:"add_groups"`
+	RemoveGroups       []string `json:"remove_groups"`
+	AddedRoles         []string `json:"added_roles"`
+	RemovedRoles       []string `json:"removed_roles"`
+	AddedPermissions   []string `json:"added_permissions"`
+	RemovedPermissions []string `json:"removed_permissions"`
+}
+
+// EffectivePermissions são os grupos, papéis e permissões globais efetivos de um
+// usuário. Em uma simulação, refletem o estado simulado.
+type EffectivePermissions struct {
+	UserID      uuid.UUID             `json:"user_id"`
+	Groups      []string              `json:"groups"`
+	Roles       []string              `json:"roles"`
+	Permissions []EffectivePermission `json:"permissions"`
+	Simulation  *PermissionSimulation `json:"simulation,omitempty"`
+}
+
+// EffectivePermissionsQuery define as opções da consulta de permissões efetivas
+type EffectivePermissionsQuery struct {
+	Explain      bool
+	AddGroups    []string
+	RemoveGroups []string
+}
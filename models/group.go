@@ -9,13 +9,15 @@ import (
 
 // Group representa um grupo de usuários com permissões específicas
 type Group struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	Name        string    `gorm:"unique;not null" json:"name"`
-	Description string    `json:"description"`
-	Users       []User    `gorm:"many2many:user_groups;" json:"-"`
-	Roles       []Role    `gorm:"many2many:group_roles;" json:"roles,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_group_org_name" json:"organization_id"`
+	Name           string    `gorm:"uniqueIndex:idx_group_org_name;not null" json:"name"`
+	Description    string    `json:"description"`
+	Users          []User    `gorm:"many2many:user_groups;" json:"-"`
+	Roles          []Role    `gorm:"many2many:group_roles;" json:"roles,omitempty"`
+	Parents        []Group   `gorm:"many2many:group_parents;joinForeignKey:GroupID;joinReferences:ParentID" json:"parents,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // BeforeCreate é um hook GORM que gera um UUID antes de criar um grupo
@@ -31,4 +33,5 @@ type GroupRequest struct {
 	Name        string   `json:"name" binding:"required"`
 	Description string   `json:"description"`
 	RoleIDs     []string `json:"role_ids"`
-}
\ No newline at end of file
+	ParentIDs   []string `json:"parent_ids"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Situações de um convite
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation representa o convite de um e-mail para uma organização, com os
+// papéis e grupos que o convidado recebe ao aceitá-lo. Apenas o hash do token
+// do link é armazenado. ExpiresInHours guarda a validade pedida na criação,
+// reaplicada a cada reenvio (zero nos convites anteriores a ela).
+type Invitation struct {
+	ID             uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID uuid.UUID    `gorm:"type:uuid;index;not null" json:"organization_id"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"-"`
+	Email          string       `gorm:"index;not null" json:"email"`
+	Roles          []Role       `gorm:"many2many:invitation_roles;" json:"-"`
+	Groups         []Group      `gorm:"many2many:invitation_groups;" json:"-"`
+	InvitedByID    uuid.UUID    `gorm:"type:uuid" json:"invited_by_id"`
+	TokenHash      string       `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt      time.Time    `gorm:"not null" json:"expires_at"`
+	ExpiresInHours int          `json:"-"`
+	SentAt         *time.Time   `json:"sent_at,omitempty"`
+	AcceptedAt     *time.Time   `json:"accepted_at,omitempty"`
+	AcceptedByID   *uuid.UUID   `gorm:"type:uuid" json:"accepted_by_id,omitempty"`
+	RevokedAt      *time.Time   `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar um convite
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// Status retorna a situação do convite no instante informado
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case now.After(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+// InvitationRequest é um modelo para convidar um e-mail para a organização.
+// Sem role_ids, o convidado recebe o papel user; sem expires_in_hours, o convite
+// vale por 7 dias.
+type InvitationRequest struct {
+	Email          string   `json:"email" binding:"required,email"`
+	RoleIDs        []string `json:"role_ids"`
+	GroupIDs       []string `json:"group_ids"`
+	ExpiresInHours int      `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// InvitationResponse é um convite com sua situação. InviteURL e EmailSent só
+// são preenchidos quando o link é emitido (criação e reenvio).
+type InvitationResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Email      string     `json:"email"`
+	Status     string     `json:"status"`
+	Roles      []string   `json:"roles"`
+	Groups     []string   `json:"groups"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	InviteURL  string     `json:"invite_url,omitempty"`
+	EmailSent  *bool      `json:"email_sent,omitempty"`
+}
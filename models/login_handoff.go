@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginHandoff guarda o resultado de um login até que o frontend o troque pelo
+// código de uso único recebido no redirecionamento. O payload (tokens e perfil)
+// fica cifrado e é apagado na troca.
+type LoginHandoff struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	CodeHash   string     `gorm:"uniqueIndex;not null" json:"-"`
+	Payload    string     `gorm:"type:text" json:"-"`
+	ExpiresAt  time.Time  `gorm:"index;not null" json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar um handoff de login
+func (h *LoginHandoff) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginState representa um parâmetro state emitido para um fluxo de login OAuth em andamento
+type LoginState struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	StateHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	Provider     string     `gorm:"not null" json:"provider"`
+	LinkUserID   *uuid.UUID `gorm:"type:uuid" json:"link_user_id,omitempty"`
+	InvitationID *uuid.UUID `gorm:"type:uuid" json:"invitation_id,omitempty"`
+	ReturnTo     string     `json:"return_to"`
+	CodeVerifier string     `json:"-"`
+	Nonce        string     `json:"-"`
+	ExpiresAt    time.Time  `gorm:"index;not null" json:"expires_at"`
+	ConsumedAt   *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar um state de login
+func (s *LoginState) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"go-google/policy"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organization é um espaço de trabalho isolado: grupos, papéis, atribuições com
+// escopo e políticas pertencem a uma organização, e os usuários participam dela
+// por meio de associações
+type Organization struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar uma organização
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// Membership associa um usuário a uma organização, com os papéis e os atributos
+// do usuário naquela organização
+type Membership struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID uuid.UUID         `gorm:"type:uuid;uniqueIndex:idx_membership;not null" json:"organization_id"`
+	Organization   Organization      `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"organization"`
+	UserID         uuid.UUID         `gorm:"type:uuid;uniqueIndex:idx_membership;index;not null" json:"user_id"`
+	User           User              `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Roles          []Role            `gorm:"many2many:membership_roles;" json:"roles"`
+	Attributes     policy.Attributes `gorm:"type:jsonb" json:"attributes,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar uma associação
+func (m *Membership) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrganizationRequest é um modelo para criar ou atualizar organizações
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// OrganizationResponse é uma organização da qual o usuário participa, com os
+// papéis atribuídos a ele e a indicação da organização ativa no token
+type OrganizationResponse struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Roles  []string  `json:"roles"`
+	Active bool      `json:"active"`
+}
+
+// OrganizationSwitchRequest é um modelo para trocar a organização ativa da sessão
+type OrganizationSwitchRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required"`
+	RefreshToken   string `json:"refresh_token"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Permission representa uma permissão registrada no catálogo. Papéis só podem
+// referenciar permissões que existam no catálogo.
+type Permission struct {
+	Name        string    `gorm:"primaryKey" json:"name"`
+	Description string    `json:"description"`
+	Module      string    `gorm:"index;not null" json:"module"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PermissionRequest é um modelo para registrar permissões no catálogo
+type PermissionRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Module      string `json:"module" binding:"required"`
+}
+
+// GetDefaultPermissions retorna as permissões registradas pelos módulos do sistema
+func GetDefaultPermissions() []Permission {
+	return []Permission{
+		{Name: "profile:read", Description: "Ler o próprio perfil", Module: "profile"},
+		{Name: "users:read", Description: "Listar e consultar usuários", Module: "users"},
+		{Name: "users:write", Description: "Alterar usuários e seus grupos", Module: "users"},
+		{Name: "groups:read", Description: "Listar e consultar grupos", Module: "groups"},
+		{Name: "groups:write", Description: "Criar, alterar e excluir grupos", Module: "groups"},
+		{Name: "roles:read", Description: "Listar e consultar papéis e o catálogo de permissões", Module: "roles"},
+		{Name: "roles:write", Description: "Criar, alterar e excluir papéis e registrar permissões", Module: "roles"},
+	}
+}
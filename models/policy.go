@@ -0,0 +1,51 @@
+package models
+
+import (
+	"go-google/policy"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Policy é uma política de acesso baseado em atributos (ABAC) que concede ou
+// nega ações quando todas as suas condições são atendidas
+type Policy struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID uuid.UUID         `gorm:"type:uuid;uniqueIndex:idx_policy_org_name" json:"organization_id"`
+	Name           string            `gorm:"uniqueIndex:idx_policy_org_name;not null" json:"name"`
+	Description    string            `json:"description"`
+	Effect         string            `gorm:"not null" json:"effect"`
+	Actions        pq.StringArray    `gorm:"type:text[]" json:"actions"`
+	Conditions     policy.Conditions `gorm:"type:jsonb;not null" json:"conditions"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar uma política
+func (p *Policy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// Rule converte a política para o formato avaliado pelo pacote policy
+func (p *Policy) Rule() policy.Policy {
+	return policy.Policy{
+		ID:         p.ID.String(),
+		Effect:     p.Effect,
+		Actions:    p.Actions,
+		Conditions: p.Conditions,
+	}
+}
+
+// PolicyRequest é um modelo para criar ou atualizar políticas
+type PolicyRequest struct {
+	Name        string             `json:"name" binding:"required"`
+	Description string             `json:"description"`
+	Effect      string             `json:"effect" binding:"required"`
+	Actions     []string           `json:"actions" binding:"required"`
+	Conditions  []policy.Condition `json:"conditions"`
+}
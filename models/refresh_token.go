@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken representa um token de atualização emitido e persistido (apenas o hash).
+// Tokens da mesma sessão compartilham o FamilyID; cada renovação gera um novo
+// registro e marca o anterior como rotacionado.
+type RefreshToken struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	FamilyID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"family_id"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	UserAgent    string     `json:"user_agent"`
+	IPAddress    string     `json:"ip_address"`
+	ExpiresAt    time.Time  `gorm:"index;not null" json:"expires_at"`
+	RotatedAt    *time.Time `json:"rotated_at,omitempty"`
+	ReplacedByID *uuid.UUID `gorm:"type:uuid" json:"replaced_by_id,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ClientInfo reúne os metadados do dispositivo que iniciou ou renovou a sessão
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"go-google/rebac"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RelationTuple armazena uma tupla de relacionamento (objeto#relação@sujeito)
+// de uma organização. SubjectRelation é vazio quando o sujeito é um objeto, e
+// não um userset.
+type RelationTuple struct {
+	ID               uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID   uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_relation_tuple_org;index:idx_relation_tuple_org_object" json:"organization_id"`
+	Namespace        string    `gorm:"uniqueIndex:idx_relation_tuple_org;index:idx_relation_tuple_org_object;not null" json:"namespace"`
+	ObjectID         string    `gorm:"uniqueIndex:idx_relation_tuple_org;index:idx_relation_tuple_org_object;not null" json:"object_id"`
+	Relation         string    `gorm:"uniqueIndex:idx_relation_tuple_org;index:idx_relation_tuple_org_object;not null" json:"relation"`
+	SubjectNamespace string    `gorm:"uniqueIndex:idx_relation_tuple_org;index:idx_relation_tuple_subject;not null" json:"subject_namespace"`
+	SubjectID        string    `gorm:"uniqueIndex:idx_relation_tuple_org;index:idx_relation_tuple_subject;not null" json:"subject_id"`
+	SubjectRelation  string    `gorm:"uniqueIndex:idx_relation_tuple_org;not null;default:''" json:"subject_relation"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar uma tupla
+func (t *RelationTuple) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// NewRelationTuple converte uma tupla da organização para o formato armazenado
+func NewRelationTuple(orgID uuid.UUID, tuple rebac.Tuple) *RelationTuple {
+	return &RelationTuple{
+		OrganizationID:   orgID,
+		Namespace:        tuple.Object.Namespace,
+		ObjectID:         tuple.Object.ID,
+		Relation:         tuple.Relation,
+		SubjectNamespace: tuple.Subject.Object.Namespace,
+		SubjectID:        tuple.Subject.Object.ID,
+		SubjectRelation:  tuple.Subject.Relation,
+	}
+}
+
+// Tuple converte a tupla armazenada para o formato avaliado pelo pacote rebac
+func (t *RelationTuple) Tuple() rebac.Tuple {
+	return rebac.Tuple{
+		Object:   rebac.Object{Namespace: t.Namespace, ID: t.ObjectID},
+		Relation: t.Relation,
+		Subject: rebac.Subject{
+			Object:   rebac.Object{Namespace: t.SubjectNamespace, ID: t.SubjectID},
+			Relation: t.SubjectRelation,
+		},
+	}
+}
+
+// RelationTupleRequest identifica uma tupla da organização nas APIs de relacionamento
+type RelationTupleRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required,uuid"`
+	Object         string `json:"object" binding:"required"`
+	Relation       string `json:"relation" binding:"required"`
+	Subject        string `json:"subject" binding:"required"`
+}
+
+// RelationExpandRequest pede a árvore de sujeitos de uma relação na organização
+type RelationExpandRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required,uuid"`
+	Object         string `json:"object" binding:"required"`
+	Relation       string `json:"relation" binding:"required"`
+}
+
+// RelationListObjectsRequest pede os objetos de um namespace com os quais o
+// sujeito tem a relação na organização
+type RelationListObjectsRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required,uuid"`
+	Namespace      string `json:"namespace" binding:"required"`
+	Relation       string `json:"relation" binding:"required"`
+	Subject        string `json:"subject" binding:"required"`
+}
+
+// RelationTupleFilter restringe a leitura de tuplas; campos vazios não filtram
+type RelationTupleFilter struct {
+	Namespace string
+	ObjectID  string
+	Relation  string
+	Subject   *rebac.Subject
+}
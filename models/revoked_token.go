@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de revogação registrados
+const (
+	// RevocationAccessToken revoga um único token de acesso pelo jti
+	RevocationAccessToken = "access_token"
+	// RevocationSession revoga todos os tokens de acesso de uma sessão (claim sid)
+	RevocationSession = "session"
+	// RevocationUser revoga todos os tokens de acesso emitidos ao usuário até RevokedAt
+	RevocationUser = "user"
+)
+
+// RevokedToken registra uma revogação de tokens de acesso ainda não expirados.
+// O registro pode ser removido após ExpiresAt, quando nenhum token afetado é mais válido.
+type RevokedToken struct {
+	Type      string    `gorm:"primaryKey" json:"type"`
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	RevokedAt time.Time `gorm:"not null" json:"revoked_at"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+}
@@ -4,20 +4,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/lib/pq" 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 // Role representa um papel/função no sistema com permissões específicas
 type Role struct {
-	ID          uuid.UUID   `gorm:"type:uuid;primary_key" json:"id"`
-	Name        string      `gorm:"unique;not null" json:"name"`
-	Description string      `json:"description"`
-	Permissions pq.StringArray    `gorm:"type:text[]" json:"permissions"`
-	Users       []User      `gorm:"many2many:user_roles;" json:"-"`
-	Groups      []Group     `gorm:"many2many:group_roles;" json:"-"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID uuid.UUID      `gorm:"type:uuid;uniqueIndex:idx_role_org_name" json:"organization_id"`
+	Name           string         `gorm:"uniqueIndex:idx_role_org_name;not null" json:"name"`
+	Description    string         `json:"description"`
+	Permissions    pq.StringArray `gorm:"type:text[]" json:"permissions"`
+	System         bool           `gorm:"not null;default:false" json:"system"`
+	Parents        []Role         `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID" json:"parents,omitempty"`
+	Groups         []Group        `gorm:"many2many:group_roles;" json:"-"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
 }
 
 // BeforeCreate é um hook GORM que gera um UUID antes de criar um papel
@@ -34,18 +36,28 @@ const (
 	RoleUser  = "user"
 )
 
-// GetDefaultRoles retorna os papéis padrão do sistema
+// GetDefaultRoles retorna os papéis padrão do sistema, criados em cada organização
 func GetDefaultRoles() []Role {
 	return []Role{
 		{
 			Name:        RoleAdmin,
-			Description: "Administrador do sistema",
+			Description: "Administrador da organização",
 			Permissions: []string{"users:read", "users:write", "groups:read", "groups:write", "roles:read", "roles:write"},
+			System:      true,
 		},
 		{
 			Name:        RoleUser,
 			Description: "Usuário comum",
 			Permissions: []string{"profile:read"},
+			System:      true,
 		},
 	}
-}
\ No newline at end of file
+}
+
+// RoleRequest é um modelo para criar ou atualizar papéis
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	ParentIDs   []string `json:"parent_ids"`
+}
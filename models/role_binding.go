@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipos de sujeito de uma atribuição de papel com escopo
+const (
+	// SubjectUser indica uma atribuição feita diretamente a um usuário
+	SubjectUser = "user"
+	// SubjectGroup indica uma atribuição feita a um grupo, válida para seus
+	// membros e para os membros dos seus subgrupos
+	SubjectGroup = "group"
+)
+
+// ResourceTypeGroup é o tipo de recurso dos grupos deste serviço, usado para
+// atribuir papéis sobre um grupo específico (por exemplo, admin de um grupo)
+const ResourceTypeGroup = "group"
+
+// RoleBinding atribui um papel a um usuário ou grupo apenas sobre um recurso
+// específico (tipo + ID), sem conceder as permissões do papel globalmente
+type RoleBinding struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index" json:"organization_id"`
+	SubjectType    string    `gorm:"uniqueIndex:idx_role_binding;not null" json:"subject_type"`
+	SubjectID      uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_role_binding;not null" json:"subject_id"`
+	RoleID         uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_role_binding;index;not null" json:"role_id"`
+	Role           Role      `gorm:"foreignKey:RoleID" json:"role"`
+	ResourceType   string    `gorm:"uniqueIndex:idx_role_binding;index:idx_role_binding_resource;not null" json:"resource_type"`
+	ResourceID     string    `gorm:"uniqueIndex:idx_role_binding;index:idx_role_binding_resource;not null" json:"resource_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar uma atribuição
+func (b *RoleBinding) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// RoleBindingRequest é um modelo para criar atribuições de papel com escopo
+type RoleBindingRequest struct {
+	SubjectType  string `json:"subject_type" binding:"required"`
+	SubjectID    string `json:"subject_id" binding:"required"`
+	RoleID       string `json:"role_id" binding:"required"`
+	ResourceType string `json:"resource_type" binding:"required"`
+	ResourceID   string `json:"resource_id" binding:"required"`
+}
+
+// RoleBindingFilter restringe a listagem de atribuições; campos vazios não filtram
+type RoleBindingFilter struct {
+	SubjectType  string
+	SubjectID    string
+	ResourceType string
+	ResourceID   string
+}
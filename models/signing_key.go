@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Estados de uma chave de assinatura no key ring
+const (
+	// SigningKeyActive indica a chave usada para assinar novos tokens
+	SigningKeyActive = "active"
+	// SigningKeyVerifyOnly indica uma chave substituída que ainda verifica os
+	// tokens emitidos com ela até VerifyUntil (período de carência)
+	SigningKeyVerifyOnly = "verify_only"
+	// SigningKeyRetired indica uma chave que não verifica mais nenhum token
+	SigningKeyRetired = "retired"
+)
+
+// SigningKey armazena uma chave assimétrica de assinatura dos tokens emitidos.
+// A chave privada fica cifrada com uma chave derivada do JWT_SECRET.
+type SigningKey struct {
+	ID          string     `gorm:"primaryKey" json:"kid"`
+	Algorithm   string     `gorm:"not null" json:"alg"`
+	PrivateKey  string     `gorm:"type:text;not null" json:"-"`
+	Status      string     `gorm:"index;not null" json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+	VerifyUntil *time.Time `gorm:"index" json:"verify_until,omitempty"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+}
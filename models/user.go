@@ -1,6 +1,7 @@
 package models
 
 import (
+	"go-google/policy"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,16 +11,20 @@ import (
 
 // User representa um usuário no sistema
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	Email        string    `gorm:"unique;not null" json:"email"`
-	Name         string    `gorm:"not null" json:"name"`
-	Picture      string    `json:"picture"`
-	GoogleID     string    `gorm:"unique;not null" json:"google_id"`
-	RefreshToken string    `gorm:"-" json:"-"`
-	Groups       []Group   `gorm:"many2many:user_groups;" json:"groups,omitempty"`
-	Roles        []Role    `gorm:"many2many:user_roles;" json:"roles,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Email         string         `gorm:"unique;not null" json:"email"`
+	Name          string         `gorm:"not null" json:"name"`
+	Picture       string         `json:"picture"`
+	RefreshTokens []RefreshToken `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Identities    []UserIdentity `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"identities,omitempty"`
+	Groups        []Group        `gorm:"many2many:user_groups;" json:"groups,omitempty"`
+	// Roles e Attributes são os da associação do usuário à organização em que
+	// ele foi carregado (UserRepository.FindMember)
+	Roles        []Role            `gorm:"-" json:"roles,omitempty"`
+	Attributes   policy.Attributes `gorm:"-" json:"attributes,omitempty"`
+	AuthzVersion int64             `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
 // BeforeCreate é um hook GORM que gera um UUID antes de criar um usuário
@@ -30,15 +35,18 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// UserResponse é um modelo para resposta de API com informações do usuário
+// UserResponse é um modelo para resposta de API com informações do usuário.
+// Grupos, papéis, permissões e atributos são os da organização OrganizationID.
 type UserResponse struct {
-	ID         uuid.UUID `json:"id"`
-	Email      string    `json:"email"`
-	Name       string    `json:"name"`
-	Picture    string    `json:"picture"`
-	Groups     []string  `json:"groups"`
-	Roles      []string  `json:"roles"`
-	Permissions pq.StringArray `json:"permissions"`
+	ID             uuid.UUID         `json:"id"`
+	OrganizationID *uuid.UUID        `json:"organization_id,omitempty"`
+	Email          string            `json:"email"`
+	Name           string            `json:"name"`
+	Picture        string            `json:"picture"`
+	Groups         []string          `json:"groups"`
+	Roles          []string          `json:"roles"`
+	Permissions    pq.StringArray    `json:"permissions"`
+	Attributes     policy.Attributes `json:"attributes,omitempty"`
 }
 
 // UserWithToken representa um usuário com tokens JWT
@@ -47,4 +55,4 @@ type UserWithToken struct {
 	AccessToken  string       `json:"access_token"`
 	RefreshToken string       `json:"refresh_token"`
 	ExpiresIn    int64        `json:"expires_in"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity representa uma identidade externa (provedor + subject) vinculada a um usuário
+type UserIdentity struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	Provider      string     `gorm:"uniqueIndex:idx_identity_provider_subject;not null" json:"provider"`
+	Subject       string     `gorm:"uniqueIndex:idx_identity_provider_subject;not null" json:"subject"`
+	Email         string     `json:"email"`
+	EmailVerified bool       `json:"email_verified"`
+	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate é um hook GORM que gera um UUID antes de criar uma identidade
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ProviderMetadata contém os campos relevantes do documento de descoberta OpenID Connect
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover busca o documento /.well-known/openid-configuration do issuer informado
+func Discover(ctx context.Context, issuer string) (*ProviderMetadata, error) {
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao preparar descoberta OIDC: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar configuração OIDC: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("resposta não-OK da descoberta OIDC: %s", resp.Status)
+	}
+
+	var metadata ProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar configuração OIDC: %w", err)
+	}
+
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("issuer divergente na descoberta OIDC: esperado %q, recebido %q", issuer, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("configuração OIDC incompleta para o issuer %q", issuer)
+	}
+
+	return &metadata, nil
+}
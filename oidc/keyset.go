@@ -0,0 +1,208 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultKeySetTTL é usado quando o provedor não informa Cache-Control
+	defaultKeySetTTL = time.Hour
+	// minRefreshInterval limita novas buscas disparadas por kid desconhecido
+	minRefreshInterval = time.Minute
+)
+
+// JSONWebKey representa uma chave pública no formato JWK (RFC 7517)
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet representa um documento JWKS
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// RemoteKeySet busca e mantém em cache as chaves públicas publicadas em uma URL JWKS
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	expiresAt   time.Time
+	lastFetchAt time.Time
+}
+
+// NewRemoteKeySet cria um conjunto de chaves remoto para a URL JWKS informada
+func NewRemoteKeySet(jwksURL string) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    jwksURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]crypto.PublicKey),
+	}
+}
+
+// KeyByID retorna a chave pública com o kid informado, atualizando o cache
+// quando ele expirou ou quando o kid ainda não é conhecido (rotação de chaves)
+func (ks *RemoteKeySet) KeyByID(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, found := ks.keys[kid]
+	fresh := time.Now().Before(ks.expiresAt)
+	canRefresh := time.Since(ks.lastFetchAt) >= minRefreshInterval
+	ks.mu.RUnlock()
+
+	if found && fresh {
+		return key, nil
+	}
+	if !fresh || canRefresh {
+		if err := ks.refresh(ctx); err != nil {
+			if found {
+				// Mantém a chave em cache se o provedor estiver indisponível
+				return key, nil
+			}
+			return nil, err
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("chave de assinatura desconhecida: %s", kid)
+}
+
+// refresh busca o documento JWKS e substitui as chaves em cache
+func (ks *RemoteKeySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar requisição JWKS: %w", err)
+	}
+
+	ks.mu.Lock()
+	ks.lastFetchAt = time.Now()
+	ks.mu.Unlock()
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("resposta não-OK do endpoint JWKS: %s", resp.Status)
+	}
+
+	var keySet JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&keySet); err != nil {
+		return fmt.Errorf("erro ao decodificar JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue // Ignorar tipos de chave não suportados
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.expiresAt = time.Now().Add(cacheTTL(resp.Header.Get("Cache-Control")))
+	ks.mu.Unlock()
+
+	return nil
+}
+
+// PublicKey converte a JWK em uma chave pública utilizável na verificação
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("módulo RSA inválido: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("expoente RSA inválido: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("curva não suportada: %s", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("coordenada X inválida: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("coordenada Y inválida: %w", err)
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("curva não suportada: %s", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("chave Ed25519 inválida")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.New("tipo de chave não suportado: " + k.KeyType)
+	}
+}
+
+// cacheTTL extrai o max-age do cabeçalho Cache-Control
+func cacheTTL(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultKeySetTTL
+}
@@ -0,0 +1,75 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyByIDRotation(t *testing.T) {
+	oldKey := generateKey(t)
+	newKey := generateKey(t)
+	jwks, server := newFakeJWKS(t, map[string]*rsa.PrivateKey{"old": oldKey})
+	keySet := NewRemoteKeySet(server.URL)
+	verifier := NewVerifier(keySet, testClientID, testIssuer)
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, signToken(t, oldKey, "old", validClaims(time.Now())), testNonce); err != nil {
+		t.Fatalf("Verify com a chave atual: %v", err)
+	}
+	if fetches := jwks.fetches(); fetches != 1 {
+		t.Fatalf("buscas do JWKS = %d, esperado 1", fetches)
+	}
+
+	jwks.publish(map[string]*rsa.PrivateKey{"old": oldKey, "new": newKey})
+	rotated := signToken(t, newKey, "new", validClaims(time.Now()))
+
+	// Dentro do intervalo mínimo, um kid desconhecido não dispara nova busca
+	if _, err := verifier.Verify(ctx, rotated, testNonce); err == nil || !strings.Contains(err.Error(), "chave de assinatura desconhecida") {
+		t.Fatalf("erro = %v, esperado chave desconhecida", err)
+	}
+	if fetches := jwks.fetches(); fetches != 1 {
+		t.Fatalf("buscas do JWKS = %d, esperado 1", fetches)
+	}
+
+	// Passado o intervalo, o kid desconhecido faz buscar o JWKS novamente
+	keySet.mu.Lock()
+	keySet.lastFetchAt = time.Now().Add(-2 * minRefreshInterval)
+	keySet.mu.Unlock()
+
+	if _, err := verifier.Verify(ctx, rotated, testNonce); err != nil {
+		t.Fatalf("Verify com a chave nova: %v", err)
+	}
+	if fetches := jwks.fetches(); fetches != 2 {
+		t.Fatalf("buscas do JWKS = %d, esperado 2", fetches)
+	}
+
+	// Chaves conhecidas e dentro da validade vêm do cache
+	if _, err := verifier.Verify(ctx, signToken(t, oldKey, "old", validClaims(time.Now())), testNonce); err != nil {
+		t.Fatalf("Verify com a chave antiga: %v", err)
+	}
+	if fetches := jwks.fetches(); fetches != 2 {
+		t.Fatalf("buscas do JWKS = %d, esperado 2", fetches)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"public, max-age=600", 10 * time.Minute},
+		{"MAX-AGE=60, must-revalidate", time.Minute},
+		{"no-cache", defaultKeySetTTL},
+		{"max-age=abc", defaultKeySetTTL},
+		{"", defaultKeySetTTL},
+	}
+
+	for _, tt := range tests {
+		if got := cacheTTL(tt.header); got != tt.want {
+			t.Errorf("cacheTTL(%q) = %v, esperado %v", tt.header, got, tt.want)
+		}
+	}
+}
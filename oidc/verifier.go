@@ -0,0 +1,116 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims reúne as informações de identidade extraídas de um ID token verificado
+type Claims struct {
+	Subject       string
+	Issuer        string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+	HostedDomain  string
+	Nonce         string
+}
+
+// Verifier valida ID tokens OpenID Connect emitidos por um provedor
+type Verifier struct {
+	issuers  []string
+	clientID string
+	keySet   *RemoteKeySet
+	now      func() time.Time
+}
+
+// NewVerifier cria um verificador de ID tokens. O primeiro issuer é o canônico;
+// os demais são aceitos como variações equivalentes (ex.: "accounts.google.com").
+func NewVerifier(keySet *RemoteKeySet, clientID string, issuers ...string) *Verifier {
+	return &Verifier{
+		issuers:  issuers,
+		clientID: clientID,
+		keySet:   keySet,
+		now:      time.Now,
+	}
+}
+
+// Verify confere assinatura, iss, aud, exp e nonce do ID token. A exigência de
+// email_verified fica a cargo de quem chama, pois nem todo provedor emite a claim.
+func (v *Verifier) Verify(ctx context.Context, rawIDToken, expectedNonce string) (*Claims, error) {
+	if rawIDToken == "" {
+		return nil, errors.New("id_token ausente na resposta do provedor")
+	}
+
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keySet.KeyByID(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithAudience(v.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(v.now),
+	)
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("id_token inválido: %w", err)
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("falha ao extrair claims do id_token")
+	}
+
+	claims := &Claims{
+		Subject:      stringClaim(mapClaims, "sub"),
+		Issuer:       stringClaim(mapClaims, "iss"),
+		Email:        stringClaim(mapClaims, "email"),
+		Name:         stringClaim(mapClaims, "name"),
+		Picture:      stringClaim(mapClaims, "picture"),
+		HostedDomain: stringClaim(mapClaims, "hd"),
+		Nonce:        stringClaim(mapClaims, "nonce"),
+	}
+
+	if !v.validIssuer(claims.Issuer) {
+		return nil, fmt.Errorf("id_token inválido: issuer inesperado %q", claims.Issuer)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id_token inválido: claim sub ausente")
+	}
+	if expectedNonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(expectedNonce)) != 1 {
+		return nil, errors.New("id_token inválido: nonce não corresponde ao login")
+	}
+
+	// Alguns provedores serializam email_verified como string
+	switch verified := mapClaims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	}
+
+	return claims, nil
+}
+
+// validIssuer verifica se o issuer do token é um dos configurados
+func (v *Verifier) validIssuer(issuer string) bool {
+	for _, expected := range v.issuers {
+		if issuer == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// stringClaim lê uma claim textual, retornando vazio se ausente
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
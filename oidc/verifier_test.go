@@ -0,0 +1,215 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://issuer.example"
+	testClientID = "client-id"
+	testNonce    = "nonce-do-login"
+)
+
+// fakeJWKS simula o endpoint JWKS do provedor, contando as buscas e permitindo
+// trocar as chaves publicadas (rotação)
+type fakeJWKS struct {
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	requests int
+}
+
+func newFakeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) (*fakeJWKS, *httptest.Server) {
+	t.Helper()
+
+	jwks := &fakeJWKS{keys: keys}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwks.mu.Lock()
+		defer jwks.mu.Unlock()
+		jwks.requests++
+
+		keySet := JSONWebKeySet{}
+		for kid, key := range jwks.keys {
+			keySet.Keys = append(keySet.Keys, JSONWebKey{
+				KeyID:   kid,
+				KeyType: "RSA",
+				Use:     "sig",
+				N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(keySet)
+	}))
+	t.Cleanup(server.Close)
+	return jwks, server
+}
+
+// publish substitui as chaves publicadas
+func (j *fakeJWKS) publish(keys map[string]*rsa.PrivateKey) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+}
+
+// fetches retorna quantas vezes o JWKS foi buscado
+func (j *fakeJWKS) fetches() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.requests
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave RSA: %v", err)
+	}
+	return key
+}
+
+// validClaims monta as claims de um ID token válido para o verificador de teste
+func validClaims(now time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            testIssuer,
+		"aud":            testClientID,
+		"sub":            "user-123",
+		"email":          "ana@example.com",
+		"email_verified": true,
+		"nonce":          testNonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("erro ao assinar token: %v", err)
+	}
+	return signed
+}
+
+func TestVerify(t *testing.T) {
+	key := generateKey(t)
+	otherKey := generateKey(t)
+	_, server := newFakeJWKS(t, map[string]*rsa.PrivateKey{"key-1": key})
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		key     *rsa.PrivateKey
+		kid     string
+		modify  func(jwt.MapClaims)
+		nonce   string
+		wantErr string
+	}{
+		{name: "token válido", key: key, kid: "key-1"},
+		{name: "assinatura inválida", key: otherKey, kid: "key-1", wantErr: "id_token inválido"},
+		{name: "issuer inesperado", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { c["iss"] = "https://outro.example" }, wantErr: "issuer inesperado"},
+		{name: "audience de outro cliente", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { c["aud"] = "outro-cliente" }, wantErr: "id_token inválido"},
+		{name: "token expirado", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { c["exp"] = now.Add(-2 * time.Minute).Unix() }, wantErr: "id_token inválido"},
+		{name: "expirado dentro da tolerância", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { c["exp"] = now.Add(-30 * time.Second).Unix() }},
+		{name: "sem exp", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: "id_token inválido"},
+		{name: "nonce divergente", key: key, kid: "key-1", nonce: "outro-nonce", wantErr: "nonce não corresponde"},
+		{name: "sem nonce", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: "nonce não corresponde"},
+		{name: "sem sub", key: key, kid: "key-1", modify: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: "claim sub ausente"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier(NewRemoteKeySet(server.URL), testClientID, testIssuer)
+			verifier.now = func() time.Time { return now }
+
+			claims := validClaims(now)
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			nonce := testNonce
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			got, err := verifier.Verify(context.Background(), signToken(t, tt.key, tt.kid, claims), nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("erro = %v, esperado %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if got.Subject != "user-123" || got.Email != "ana@example.com" || !got.EmailVerified {
+				t.Errorf("claims = %+v", got)
+			}
+		})
+	}
+}
+
+func TestVerifyEmailVerified(t *testing.T) {
+	key := generateKey(t)
+	_, server := newFakeJWKS(t, map[string]*rsa.PrivateKey{"key-1": key})
+	verifier := NewVerifier(NewRemoteKeySet(server.URL), testClientID, testIssuer)
+
+	tests := []struct {
+		name  string
+		value any
+		want  bool
+	}{
+		{"booleano verdadeiro", true, true},
+		{"booleano falso", false, false},
+		{"texto verdadeiro", "true", true},
+		{"texto falso", "false", false},
+		{"ausente", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims(time.Now())
+			if tt.value == nil {
+				delete(claims, "email_verified")
+			} else {
+				claims["email_verified"] = tt.value
+			}
+
+			got, err := verifier.Verify(context.Background(), signToken(t, key, "key-1", claims), testNonce)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if got.EmailVerified != tt.want {
+				t.Errorf("EmailVerified = %v, esperado %v", got.EmailVerified, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAcceptsIssuerVariants(t *testing.T) {
+	key := generateKey(t)
+	_, server := newFakeJWKS(t, map[string]*rsa.PrivateKey{"key-1": key})
+	verifier := NewVerifier(NewRemoteKeySet(server.URL), testClientID, testIssuer, "issuer.example")
+
+	claims := validClaims(time.Now())
+	claims["iss"] = "issuer.example"
+	if _, err := verifier.Verify(context.Background(), signToken(t, key, "key-1", claims), testNonce); err != nil {
+		t.Fatalf("Verify com issuer alternativo: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+// Package permission implementa a gramática das permissões e a verificação de
+// uma permissão exigida contra as permissões concedidas.
+//
+// Uma permissão é formada por segmentos separados por ":", em que o último
+// segmento é a ação e os anteriores identificam o recurso (users:read,
+// docs:reports:read). Permissões concedidas podem usar curingas:
+//
+//   - "*" sozinho concede todas as permissões (superusuário)
+//   - um segmento "*" no recurso corresponde a qualquer segmento na mesma posição (*:read)
+//   - a ação "*" concede todas as ações do recurso e dos recursos abaixo dele
+//     (users:* concede users:read e users:profile:read)
+//
+// Algumas ações implicam outras: por padrão, write implica read.
+package permission
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// Wildcard corresponde a qualquer segmento e, sozinho, a qualquer permissão
+	Wildcard = "*"
+	// Separator separa os segmentos de recurso e a ação
+	Separator = ":"
+)
+
+// segmentPattern define os caracteres aceitos em um segmento concreto
+var segmentPattern = regexp.MustCompile(`^[a-z0-9_.-]+$`)
+
+// Matcher verifica permissões considerando curingas e ações implícitas
+type Matcher struct {
+	implied map[string][]string
+}
+
+// NewMatcher cria um verificador em que cada ação concede também as ações
+// listadas em implied, de forma transitiva
+func NewMatcher(implied map[string][]string) *Matcher {
+	return &Matcher{implied: implied}
+}
+
+// Default é o verificador usado pelo sistema, em que write implica read
+var Default = NewMatcher(map[string][]string{
+	"write": {"read"},
+})
+
+// Valid indica se o nome é uma permissão concreta (sem curingas), como as
+// registradas no catálogo
+func Valid(name string) bool {
+	segments := strings.Split(name, Separator)
+	if len(segments) < 2 {
+		return false
+	}
+	for _, segment := range segments {
+		if !segmentPattern.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidPattern indica se o nome é uma permissão válida para ser concedida,
+// concreta ou com curingas
+func ValidPattern(name string) bool {
+	if name == Wildcard {
+		return true
+	}
+	segments := strings.Split(name, Separator)
+	if len(segments) < 2 {
+		return false
+	}
+	for _, segment := range segments {
+		if segment != Wildcard && !segmentPattern.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPattern indica se a permissão contém curingas
+func IsPattern(name string) bool {
+	return name == Wildcard || strings.Contains(name, Wildcard)
+}
+
+// Matches indica se a permissão concedida cobre a permissão exigida
+func (m *Matcher) Matches(granted, required string) bool {
+	if granted == Wildcard || granted == required {
+		return true
+	}
+	if required == Wildcard {
+		return false
+	}
+
+	grantedResource, grantedAction, ok := split(granted)
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := split(required)
+	if !ok {
+		return false
+	}
+
+	// A ação curinga cobre o recurso e todos os recursos abaixo dele
+	if grantedAction == Wildcard {
+		return len(grantedResource) <= len(requiredResource) &&
+			segmentsMatch(grantedResource, requiredResource[:len(grantedResource)])
+	}
+
+	return len(grantedResource) == len(requiredResource) &&
+		segmentsMatch(grantedResource, requiredResource) &&
+		m.actionCovers(grantedAction, requiredAction)
+}
+
+// Allowed indica se alguma das permissões concedidas cobre a permissão exigida
+func (m *Matcher) Allowed(granted []string, required string) bool {
+	for _, perm := range granted {
+		if m.Matches(perm, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize remove duplicatas e as permissões já cobertas por outra permissão
+// da lista, preservando a ordem original
+func (m *Matcher) Normalize(granted []string) []string {
+	unique := []string{}
+	seen := make(map[string]bool)
+	for _, perm := range granted {
+		if !seen[perm] {
+			seen[perm] = true
+			unique = append(unique, perm)
+		}
+	}
+
+	normalized := []string{}
+	for i, perm := range unique {
+		covered := false
+		for j, other := range unique {
+			if i != j && m.Matches(other, perm) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			normalized = append(normalized, perm)
+		}
+	}
+	return normalized
+}
+
+// actionCovers indica se a ação concedida é a ação exigida ou a implica
+func (m *Matcher) actionCovers(granted, required string) bool {
+	if granted == required {
+		return true
+	}
+
+	visited := map[string]bool{granted: true}
+	pending := []string{granted}
+	for len(pending) > 0 {
+		action := pending[0]
+		pending = pending[1:]
+		for _, implied := range m.implied[action] {
+			if implied == required {
+				return true
+			}
+			if !visited[implied] {
+				visited[implied] = true
+				pending = append(pending, implied)
+			}
+		}
+	}
+	return false
+}
+
+// Matches verifica a permissão com o verificador padrão
+func Matches(granted, required string) bool {
+	return Default.Matches(granted, required)
+}
+
+// Allowed verifica a permissão exigida contra a lista com o verificador padrão
+func Allowed(granted []string, required string) bool {
+	return Default.Allowed(granted, required)
+}
+
+// Normalize simplifica a lista de permissões com o verificador padrão
+func Normalize(granted []string) []string {
+	return Default.Normalize(granted)
+}
+
+// split separa os segmentos de recurso da ação
+func split(name string) ([]string, string, bool) {
+	segments := strings.Split(name, Separator)
+	if len(segments) < 2 {
+		return nil, "", false
+	}
+	return segments[:len(segments)-1], segments[len(segments)-1], true
+}
+
+// segmentsMatch compara os segmentos posição a posição, aceitando curingas concedidos
+func segmentsMatch(granted, required []string) bool {
+	for i := range granted {
+		if granted[i] != Wildcard && granted[i] != required[i] {
+			return false
+		}
+	}
+	return true
+}
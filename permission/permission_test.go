@@ -0,0 +1,157 @@
+package permission
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  string
+		required string
+		want     bool
+	}{
+		{"igual", "users:read", "users:read", true},
+		{"ação diferente", "users:read", "users:write", false},
+		{"recurso diferente", "users:read", "groups:read", false},
+		{"superusuário", "*", "users:read", true},
+		{"superusuário com recurso aninhado", "*", "docs:reports:read", true},
+		{"superusuário cobre curinga", "*", "*", true},
+		{"curinga exigido sem superusuário", "users:*", "*", false},
+		{"ação curinga", "users:*", "users:read", true},
+		{"ação curinga em outro recurso", "users:*", "groups:read", false},
+		{"ação curinga cobre recurso aninhado", "docs:*", "docs:reports:read", true},
+		{"ação curinga não cobre recurso pai", "docs:reports:*", "docs:read", false},
+		{"recurso curinga", "*:read", "users:read", true},
+		{"recurso curinga com outra ação", "*:read", "users:write", false},
+		{"recurso curinga com ação implícita", "*:write", "users:read", true},
+		{"recurso curinga não cobre recurso aninhado", "*:read", "docs:reports:read", false},
+		{"curinga em segmento intermediário", "docs:*:read", "docs:reports:read", true},
+		{"curinga em segmento intermediário com outro recurso", "docs:*:read", "files:reports:read", false},
+		{"write implica read", "users:write", "users:read", true},
+		{"read não implica write", "users:read", "users:write", false},
+		{"implicação respeita o recurso", "users:write", "groups:read", false},
+		{"recurso aninhado exato", "docs:reports:read", "docs:reports:read", true},
+		{"recurso aninhado não cobre o pai", "docs:reports:read", "docs:read", false},
+		{"padrão exigido coberto por padrão igual", "users:*", "users:*", true},
+		{"padrão exigido não coberto por concreta", "users:write", "users:*", false},
+		{"concedida sem ação", "users", "users:read", false},
+		{"exigida sem ação", "users:read", "users", false},
+		{"vazias", "", "users:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.granted, tt.required); got != tt.want {
+				t.Errorf("Matches(%q, %q) = %v, esperado %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherTransitiveImplications(t *testing.T) {
+	matcher := NewMatcher(map[string][]string{
+		"admin": {"write", "delete"},
+		"write": {"read"},
+		"read":  {"write"}, // ciclo não deve travar a verificação
+	})
+
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{"users:admin", "users:write", true},
+		{"users:admin", "users:delete", true},
+		{"users:admin", "users:read", true},
+		{"users:read", "users:write", true},
+		{"users:read", "users:admin", false},
+		{"users:delete", "users:read", false},
+	}
+
+	for _, tt := range tests {
+		if got := matcher.Matches(tt.granted, tt.required); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, esperado %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required string
+		want     bool
+	}{
+		{"lista vazia", nil, "users:read", false},
+		{"nenhuma cobre", []string{"groups:read", "profile:read"}, "users:read", false},
+		{"uma cobre", []string{"groups:read", "users:*"}, "users:write", true},
+		{"implicação", []string{"users:write"}, "users:read", true},
+		{"superusuário", []string{"*"}, "roles:write", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allowed(tt.granted, tt.required); got != tt.want {
+				t.Errorf("Allowed(%v, %q) = %v, esperado %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		want    []string
+	}{
+		{"vazia", nil, []string{}},
+		{"sem redundância", []string{"users:read", "groups:read"}, []string{"users:read", "groups:read"}},
+		{"duplicatas", []string{"users:read", "users:read"}, []string{"users:read"}},
+		{"implicação", []string{"users:read", "users:write"}, []string{"users:write"}},
+		{"ação curinga", []string{"users:read", "users:*", "users:profile:read"}, []string{"users:*"}},
+		{"recurso curinga", []string{"*:read", "users:read", "users:write"}, []string{"*:read", "users:write"}},
+		{"superusuário", []string{"users:read", "*", "groups:*"}, []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.granted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize(%v) = %v, esperado %v", tt.granted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name         string
+		valid        bool
+		validPattern bool
+	}{
+		{"users:read", true, true},
+		{"docs:reports:read", true, true},
+		{"billing.invoices:export_csv", true, true},
+		{"*", false, true},
+		{"users:*", false, true},
+		{"*:read", false, true},
+		{"docs:*:read", false, true},
+		{"users", false, false},
+		{"", false, false},
+		{"users:", false, false},
+		{":read", false, false},
+		{"Users:Read", false, false},
+		{"users:re ad", false, false},
+		{"users:re*", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.name); got != tt.valid {
+			t.Errorf("Valid(%q) = %v, esperado %v", tt.name, got, tt.valid)
+		}
+		if got := ValidPattern(tt.name); got != tt.validPattern {
+			t.Errorf("ValidPattern(%q) = %v, esperado %v", tt.name, got, tt.validPattern)
+		}
+	}
+}
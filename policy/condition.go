@@ -0,0 +1,429 @@
+package policy
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	// Os fusos das condições não dependem do banco de fusos do sistema
+	_ "time/tzdata"
+)
+
+// Operadores das condições
+const (
+	OpEquals         = "eq"
+	OpNotEquals      = "ne"
+	OpIn             = "in"
+	OpNotIn          = "not_in"
+	OpContains       = "contains"
+	OpGreater        = "gt"
+	OpGreaterOrEqual = "gte"
+	OpLess           = "lt"
+	OpLessOrEqual    = "lte"
+	OpExists         = "exists"
+	OpNotExists      = "not_exists"
+	// OpTimeBetween compara a hora do dia com um intervalo ["09:00", "18:00"];
+	// intervalos que passam da meia-noite (["22:00", "06:00"]) são aceitos
+	OpTimeBetween = "time_between"
+	// OpWeekdayIn compara o dia da semana com uma lista (["mon", "tue"])
+	OpWeekdayIn = "weekday_in"
+	// OpIPIn compara um IP com uma lista de IPs ou faixas CIDR
+	OpIPIn = "ip_in"
+)
+
+// timeOfDayLayout é o formato dos limites de OpTimeBetween
+const timeOfDayLayout = "15:04"
+
+// weekdays mapeia os nomes aceitos em OpWeekdayIn
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Condition compara um atributo com um valor fixo (Value) ou com outro atributo
+// (ValueFrom). Uma condição sobre um atributo ausente não é atendida, exceto com
+// OpNotExists e, em políticas de negação, com os operadores negados (OpNotEquals
+// e OpNotIn). Timezone define o fuso de OpTimeBetween e OpWeekdayIn (padrão UTC).
+type Condition struct {
+	Attribute string `json:"attribute"`
+	Operator  string `json:"operator"`
+	Value     any    `json:"value,omitempty"`
+	ValueFrom string `json:"value_from,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+}
+
+// Conditions é a lista de condições de uma política, gravada como JSON no banco
+type Conditions []Condition
+
+// Value serializa as condições como JSON para o banco
+func (c Conditions) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan lê as condições gravadas como JSON no banco
+func (c *Conditions) Scan(value any) error {
+	return scanJSON(value, c)
+}
+
+// Matches indica se a condição é atendida na requisição. Uma condição sobre um
+// atributo ausente não é atendida.
+func (c Condition) Matches(req Request) bool {
+	matched, _ := c.evaluate(req)
+	return matched
+}
+
+// evaluate avalia a condição, indicando em known se os atributos comparados
+// estavam presentes
+func (c Condition) evaluate(req Request) (matched, known bool) {
+	actual, found := lookup(req, c.Attribute)
+	switch c.Operator {
+	case OpExists:
+		return found, true
+	case OpNotExists:
+		return !found, true
+	}
+	if !found {
+		return false, false
+	}
+
+	expected := c.Value
+	if c.ValueFrom != "" {
+		if expected, found = lookup(req, c.ValueFrom); !found {
+			return false, false
+		}
+	}
+	return c.compare(actual, expected), true
+}
+
+// negated indica se o operador é a negação de uma comparação
+func (c Condition) negated() bool {
+	return c.Operator == OpNotEquals || c.Operator == OpNotIn
+}
+
+// compare aplica o operador aos valores presentes
+func (c Condition) compare(actual, expected any) bool {
+	switch c.Operator {
+	case OpEquals:
+		return equal(actual, expected)
+	case OpNotEquals:
+		return !equal(actual, expected)
+	case OpIn:
+		return containsValue(expected, actual)
+	case OpNotIn:
+		return !containsValue(expected, actual)
+	case OpContains:
+		return containsValue(actual, expected)
+	case OpGreater, OpGreaterOrEqual, OpLess, OpLessOrEqual:
+		return compareNumbers(c.Operator, actual, expected)
+	case OpTimeBetween:
+		return c.timeBetween(actual, expected)
+	case OpWeekdayIn:
+		return c.weekdayIn(actual, expected)
+	case OpIPIn:
+		return ipIn(actual, expected)
+	default:
+		return false
+	}
+}
+
+// Validate verifica o atributo, o operador e o formato do valor esperado
+func (c Condition) Validate() error {
+	if !validPath(c.Attribute) {
+		return fmt.Errorf("atributo inválido %q: use action ou os prefixos subject., resource. e context.", c.Attribute)
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("fuso horário inválido %q", c.Timezone)
+		}
+	}
+
+	switch c.Operator {
+	case OpExists, OpNotExists:
+		return nil
+	case OpEquals, OpNotEquals, OpIn, OpNotIn, OpContains, OpGreater, OpGreaterOrEqual,
+		OpLess, OpLessOrEqual, OpTimeBetween, OpWeekdayIn, OpIPIn:
+	default:
+		return fmt.Errorf("operador desconhecido %q", c.Operator)
+	}
+
+	if (c.Value == nil) == (c.ValueFrom == "") {
+		return errors.New("informe value ou value_from")
+	}
+	if c.ValueFrom != "" {
+		if !validPath(c.ValueFrom) {
+			return fmt.Errorf("value_from inválido %q", c.ValueFrom)
+		}
+		return nil
+	}
+
+	switch c.Operator {
+	case OpIn, OpNotIn:
+		if _, ok := list(c.Value); !ok {
+			return fmt.Errorf("o operador %s exige uma lista", c.Operator)
+		}
+	case OpGreater, OpGreaterOrEqual, OpLess, OpLessOrEqual:
+		if _, ok := number(c.Value); !ok {
+			return fmt.Errorf("o operador %s exige um número", c.Operator)
+		}
+	case OpTimeBetween:
+		if _, _, ok := timeRange(c.Value); !ok {
+			return errors.New(`o operador time_between exige ["HH:MM", "HH:MM"]`)
+		}
+	case OpWeekdayIn:
+		items, ok := texts(c.Value)
+		if !ok {
+			return errors.New(`o operador weekday_in exige uma lista como ["mon", "fri"]`)
+		}
+		for _, item := range items {
+			if _, ok := weekdays[item]; !ok {
+				return fmt.Errorf("dia da semana inválido %q", item)
+			}
+		}
+	case OpIPIn:
+		items, ok := texts(c.Value)
+		if !ok {
+			return errors.New("o operador ip_in exige uma lista de IPs ou faixas CIDR")
+		}
+		for _, item := range items {
+			if _, ok := parseNetwork(item); !ok {
+				return fmt.Errorf("IP ou faixa CIDR inválida %q", item)
+			}
+		}
+	}
+	return nil
+}
+
+// location retorna o fuso da condição
+func (c Condition) location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// timeBetween indica se a hora do dia do atributo está no intervalo [início, fim)
+func (c Condition) timeBetween(actual, expected any) bool {
+	moment, ok := toTime(actual)
+	if !ok {
+		return false
+	}
+	from, to, ok := timeRange(expected)
+	if !ok {
+		return false
+	}
+
+	moment = moment.In(c.location())
+	minute := moment.Hour()*60 + moment.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// weekdayIn indica se o dia da semana do atributo está na lista
+func (c Condition) weekdayIn(actual, expected any) bool {
+	moment, ok := toTime(actual)
+	if !ok {
+		return false
+	}
+	items, ok := texts(expected)
+	if !ok {
+		return false
+	}
+
+	weekday := moment.In(c.location()).Weekday()
+	for _, item := range items {
+		if day, ok := weekdays[item]; ok && day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ipIn indica se o IP do atributo pertence a algum dos IPs ou faixas da lista
+func ipIn(actual, expected any) bool {
+	text, ok := actual.(string)
+	if !ok {
+		return false
+	}
+	ip := net.ParseIP(text)
+	if ip == nil {
+		return false
+	}
+	items, ok := texts(expected)
+	if !ok {
+		return false
+	}
+
+	for _, item := range items {
+		if network, ok := parseNetwork(item); ok && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetwork interpreta uma faixa CIDR ou um IP isolado
+func parseNetwork(value string) (*net.IPNet, bool) {
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network, true
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, false
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, true
+}
+
+// timeRange interpreta os limites de OpTimeBetween em minutos do dia
+func timeRange(value any) (int, int, bool) {
+	items, ok := texts(value)
+	if !ok || len(items) != 2 {
+		return 0, 0, false
+	}
+	from, err := time.Parse(timeOfDayLayout, items[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	to, err := time.Parse(timeOfDayLayout, items[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), true
+}
+
+// toTime aceita time.Time ou texto no formato RFC 3339
+func toTime(value any) (time.Time, bool) {
+	switch typed := value.(type) {
+	case time.Time:
+		return typed, true
+	case string:
+		moment, err := time.Parse(time.RFC3339, typed)
+		return moment, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// equal compara dois valores, tratando todos os tipos numéricos como float64 e
+// listas de qualquer tipo como []any
+func equal(a, b any) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// containsValue indica se a lista contém o valor
+func containsValue(collection, value any) bool {
+	items, ok := list(collection)
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareNumbers aplica um operador de comparação numérica
+func compareNumbers(operator string, actual, expected any) bool {
+	a, ok := number(actual)
+	if !ok {
+		return false
+	}
+	b, ok := number(expected)
+	if !ok {
+		return false
+	}
+
+	switch operator {
+	case OpGreater:
+		return a > b
+	case OpGreaterOrEqual:
+		return a >= b
+	case OpLess:
+		return a < b
+	default:
+		return a <= b
+	}
+}
+
+// number converte qualquer tipo numérico para float64
+func number(value any) (float64, bool) {
+	normalized, ok := normalize(value).(float64)
+	return normalized, ok
+}
+
+// list converte qualquer slice para []any
+func list(value any) ([]any, bool) {
+	items, ok := normalize(value).([]any)
+	return items, ok
+}
+
+// texts converte uma lista de textos para []string
+func texts(value any) ([]string, bool) {
+	items, ok := list(value)
+	if !ok {
+		return nil, false
+	}
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		text, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		texts = append(texts, text)
+	}
+	return texts, true
+}
+
+// normalize converte números para float64 e slices para []any, recursivamente,
+// para que valores vindos do JSON e do código sejam comparáveis
+func normalize(value any) any {
+	if value == nil {
+		return nil
+	}
+	if _, ok := value.(time.Time); ok {
+		return value
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = normalize(v.Index(i).Interface())
+		}
+		return items
+	default:
+		return value
+	}
+}
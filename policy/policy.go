@@ -0,0 +1,237 @@
+// Package policy implementa as políticas de controle de acesso baseado em
+// atributos (ABAC).
+//
+// Uma política concede (allow) ou nega (deny) um conjunto de ações quando todas
+// as suas condições são atendidas. As condições comparam atributos do sujeito
+// (subject.*), do recurso (resource.*), do contexto da requisição (context.*,
+// como horário e IP) e a própria ação (action):
+//
+//	{"attribute": "resource.region", "operator": "eq", "value_from": "subject.region"}
+//	{"attribute": "context.time", "operator": "time_between", "value": ["09:00", "18:00"], "timezone": "America/Sao_Paulo"}
+//
+// A avaliação segue a semântica deny-overrides: qualquer política de negação
+// aplicável prevalece sobre as de concessão. Atributos ausentes nunca concedem
+// acesso, mas também não desativam uma negação por ne ou not_in. Quando nenhuma
+// política se aplica, a decisão é NotApplicable e cabe ao chamador decidir (por
+// exemplo, pelas permissões dos papéis).
+package policy
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-google/permission"
+	"strings"
+)
+
+// Efeitos das políticas e das decisões
+const (
+	// EffectAllow concede as ações da política
+	EffectAllow = "allow"
+	// EffectDeny nega as ações da política, prevalecendo sobre qualquer concessão
+	EffectDeny = "deny"
+	// NotApplicable indica que nenhuma política se aplica à requisição
+	NotApplicable = "not_applicable"
+)
+
+// Prefixos dos atributos referenciados nas condições
+const (
+	SubjectPrefix  = "subject"
+	ResourcePrefix = "resource"
+	ContextPrefix  = "context"
+	// ActionAttribute referencia a ação avaliada
+	ActionAttribute = "action"
+)
+
+// actionMatcher compara as ações das políticas sem ações implícitas, para que
+// negar users:write não negue também users:read
+var actionMatcher = permission.NewMatcher(nil)
+
+// Attributes são os atributos de um sujeito, recurso ou contexto. Valores
+// aninhados são acessados por caminhos com ponto (subject.address.country).
+type Attributes map[string]any
+
+// Value serializa os atributos como JSON para o banco
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan lê os atributos gravados como JSON no banco
+func (a *Attributes) Scan(value any) error {
+	return scanJSON(value, a)
+}
+
+// Policy é uma regra que concede ou nega ações quando todas as condições são atendidas
+type Policy struct {
+	ID         string
+	Effect     string
+	Actions    []string
+	Conditions []Condition
+}
+
+// Request descreve o acesso avaliado
+type Request struct {
+	Action   string
+	Subject  Attributes
+	Resource Attributes
+	Context  Attributes
+}
+
+// Decision é o resultado da avaliação, com o ID da política que a determinou
+// para fins de auditoria
+type Decision struct {
+	Effect   string `json:"effect"`
+	PolicyID string `json:"policy_id,omitempty"`
+}
+
+// Allowed indica se uma política concedeu o acesso
+func (d Decision) Allowed() bool {
+	return d.Effect == EffectAllow
+}
+
+// Denied indica se uma política negou o acesso
+func (d Decision) Denied() bool {
+	return d.Effect == EffectDeny
+}
+
+// Evaluate avalia as políticas na ordem recebida. A primeira política de negação
+// aplicável determina a decisão; sem negações, vale a primeira concessão aplicável.
+func Evaluate(policies []Policy, req Request) Decision {
+	var allow *Policy
+	for i := range policies {
+		p := &policies[i]
+		if !p.Applies(req) {
+			continue
+		}
+		if p.Effect == EffectDeny {
+			return Decision{Effect: EffectDeny, PolicyID: p.ID}
+		}
+		if allow == nil {
+			allow = p
+		}
+	}
+
+	if allow != nil {
+		return Decision{Effect: EffectAllow, PolicyID: allow.ID}
+	}
+	return Decision{Effect: NotApplicable}
+}
+
+// Applies indica se a política cobre a ação e se todas as condições são
+// atendidas. Em políticas de negação, uma condição negada (ne, not_in) sobre um
+// atributo ausente é considerada atendida, para que a falta do atributo não
+// desative a negação.
+func (p Policy) Applies(req Request) bool {
+	if !actionMatcher.Allowed(p.Actions, req.Action) {
+		return false
+	}
+	for _, condition := range p.Conditions {
+		matched, known := condition.evaluate(req)
+		if !known && p.Effect == EffectDeny && condition.negated() {
+			continue
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate verifica o efeito, as ações e as condições da política
+func Validate(p Policy) error {
+	if p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return fmt.Errorf("efeito inválido %q: use allow ou deny", p.Effect)
+	}
+	if len(p.Actions) == 0 {
+		return errors.New("informe ao menos uma ação")
+	}
+	for _, action := range p.Actions {
+		if !permission.ValidPattern(action) {
+			return fmt.Errorf("ação inválida %q: use o formato recurso:ação", action)
+		}
+	}
+	for i, condition := range p.Conditions {
+		if err := condition.Validate(); err != nil {
+			return fmt.Errorf("condição %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// lookup resolve o caminho de um atributo na requisição
+func lookup(req Request, path string) (any, bool) {
+	if path == ActionAttribute {
+		return req.Action, req.Action != ""
+	}
+
+	prefix, rest, ok := strings.Cut(path, ".")
+	if !ok {
+		return nil, false
+	}
+
+	var current any
+	switch prefix {
+	case SubjectPrefix:
+		current = map[string]any(req.Subject)
+	case ResourcePrefix:
+		current = map[string]any(req.Resource)
+	case ContextPrefix:
+		current = map[string]any(req.Context)
+	default:
+		return nil, false
+	}
+
+	for _, key := range strings.Split(rest, ".") {
+		var values map[string]any
+		switch typed := current.(type) {
+		case map[string]any:
+			values = typed
+		case Attributes:
+			values = typed
+		default:
+			return nil, false
+		}
+		value, found := values[key]
+		if !found || value == nil {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+// validPath indica se o caminho referencia a ação ou um atributo com prefixo conhecido
+func validPath(path string) bool {
+	if path == ActionAttribute {
+		return true
+	}
+
+	prefix, rest, ok := strings.Cut(path, ".")
+	if !ok || (prefix != SubjectPrefix && prefix != ResourcePrefix && prefix != ContextPrefix) {
+		return false
+	}
+	for _, key := range strings.Split(rest, ".") {
+		if key == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// scanJSON decodifica um valor JSON lido do banco
+func scanJSON(value any, target any) error {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(typed, target)
+	case string:
+		return json.Unmarshal([]byte(typed), target)
+	default:
+		return fmt.Errorf("tipo não suportado para JSON: %T", value)
+	}
+}
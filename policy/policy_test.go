@@ -0,0 +1,218 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConditionMatches(t *testing.T) {
+	// Quarta-feira, 14:30 em UTC (11:30 em São Paulo)
+	now := time.Date(2026, 3, 4, 14, 30, 0, 0, time.UTC)
+	req := Request{
+		Action: "users:read",
+		Subject: Attributes{
+			"id":     "u1",
+			"region": "south",
+			"groups": []string{"support", "engineering"},
+			"level":  3,
+			"address": map[string]any{
+				"country": "br",
+			},
+		},
+		Resource: Attributes{
+			"region": "south",
+			"owner":  "u2",
+		},
+		Context: Attributes{
+			"time": now,
+			"ip":   "10.1.2.3",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"igual", Condition{Attribute: "subject.region", Operator: OpEquals, Value: "south"}, true},
+		{"diferente", Condition{Attribute: "subject.region", Operator: OpEquals, Value: "north"}, false},
+		{"igual a outro atributo", Condition{Attribute: "resource.region", Operator: OpEquals, ValueFrom: "subject.region"}, true},
+		{"ne com outro atributo", Condition{Attribute: "resource.owner", Operator: OpNotEquals, ValueFrom: "subject.id"}, true},
+		{"atributo aninhado", Condition{Attribute: "subject.address.country", Operator: OpEquals, Value: "br"}, true},
+		{"ação", Condition{Attribute: "action", Operator: OpEquals, Value: "users:read"}, true},
+		{"in", Condition{Attribute: "subject.region", Operator: OpIn, Value: []any{"north", "south"}}, true},
+		{"not_in", Condition{Attribute: "subject.region", Operator: OpNotIn, Value: []any{"north"}}, true},
+		{"contains", Condition{Attribute: "subject.groups", Operator: OpContains, Value: "support"}, true},
+		{"contains ausente", Condition{Attribute: "subject.groups", Operator: OpContains, Value: "finance"}, false},
+		{"número do código contra JSON", Condition{Attribute: "subject.level", Operator: OpEquals, Value: float64(3)}, true},
+		{"gte", Condition{Attribute: "subject.level", Operator: OpGreaterOrEqual, Value: float64(3)}, true},
+		{"lt", Condition{Attribute: "subject.level", Operator: OpLess, Value: float64(3)}, false},
+		{"exists", Condition{Attribute: "subject.region", Operator: OpExists}, true},
+		{"not_exists", Condition{Attribute: "subject.department", Operator: OpNotExists}, true},
+		{"ne com atributo ausente", Condition{Attribute: "subject.department", Operator: OpNotEquals, Value: "sales"}, false},
+		{"value_from ausente", Condition{Attribute: "subject.region", Operator: OpEquals, ValueFrom: "resource.department"}, false},
+		{"horário comercial em UTC", Condition{Attribute: "context.time", Operator: OpTimeBetween, Value: []any{"09:00", "18:00"}}, true},
+		{"horário com fuso", Condition{Attribute: "context.time", Operator: OpTimeBetween, Value: []any{"12:00", "18:00"}, Timezone: "America/Sao_Paulo"}, false},
+		{"horário noturno", Condition{Attribute: "context.time", Operator: OpTimeBetween, Value: []any{"22:00", "06:00"}}, false},
+		{"dia útil", Condition{Attribute: "context.time", Operator: OpWeekdayIn, Value: []any{"mon", "tue", "wed", "thu", "fri"}}, true},
+		{"fim de semana", Condition{Attribute: "context.time", Operator: OpWeekdayIn, Value: []any{"sat", "sun"}}, false},
+		{"IP na faixa", Condition{Attribute: "context.ip", Operator: OpIPIn, Value: []any{"10.0.0.0/8"}}, true},
+		{"IP exato", Condition{Attribute: "context.ip", Operator: OpIPIn, Value: []any{"10.1.2.3"}}, true},
+		{"IP fora da faixa", Condition{Attribute: "context.ip", Operator: OpIPIn, Value: []any{"192.168.0.0/16"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Matches(req); got != tt.want {
+				t.Errorf("Matches(%+v) = %v, esperado %v", tt.condition, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateDenyWithMissingAttribute(t *testing.T) {
+	otherRegion := Condition{Attribute: "resource.region", Operator: OpNotEquals, ValueFrom: "subject.region"}
+	outsideTeams := Condition{Attribute: "subject.team", Operator: OpNotIn, Value: []any{"support"}}
+	policies := []Policy{
+		{ID: "other-region", Effect: EffectDeny, Actions: []string{"users:read"}, Conditions: []Condition{otherRegion}},
+		{ID: "outside-teams", Effect: EffectDeny, Actions: []string{"users:write"}, Conditions: []Condition{outsideTeams}},
+		{ID: "blocked", Effect: EffectDeny, Actions: []string{"*"}, Conditions: []Condition{{Attribute: "subject.blocked", Operator: OpEquals, Value: true}}},
+		{ID: "read-write", Effect: EffectAllow, Actions: []string{"users:read", "users:write"}, Conditions: []Condition{otherRegion}},
+	}
+
+	tests := []struct {
+		name     string
+		req      Request
+		effect   string
+		policyID string
+	}{
+		{
+			"mesma região",
+			Request{Action: "users:read", Subject: Attributes{"region": "south"}, Resource: Attributes{"region": "south"}},
+			NotApplicable, "",
+		},
+		{
+			"região do sujeito ausente",
+			Request{Action: "users:read", Resource: Attributes{"region": "south"}},
+			EffectDeny, "other-region",
+		},
+		{
+			"região do recurso ausente",
+			Request{Action: "users:read", Subject: Attributes{"region": "south"}},
+			EffectDeny, "other-region",
+		},
+		{
+			"not_in com atributo ausente",
+			Request{Action: "users:write", Subject: Attributes{"region": "south"}, Resource: Attributes{"region": "south"}},
+			EffectDeny, "outside-teams",
+		},
+		{
+			"eq com atributo ausente não nega",
+			Request{Action: "users:write", Subject: Attributes{"region": "south", "team": "support"}, Resource: Attributes{"region": "south"}},
+			NotApplicable, "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := Evaluate(policies, tt.req)
+			if decision.Effect != tt.effect || decision.PolicyID != tt.policyID {
+				t.Errorf("Evaluate = %+v, esperado %s/%s", decision, tt.effect, tt.policyID)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	sameRegion := Condition{Attribute: "resource.region", Operator: OpEquals, ValueFrom: "subject.region"}
+	support := Condition{Attribute: "subject.groups", Operator: OpContains, Value: "support"}
+	policies := []Policy{
+		{ID: "support-region", Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{support, sameRegion}},
+		{ID: "all-read", Effect: EffectAllow, Actions: []string{"*:read"}, Conditions: []Condition{{Attribute: "subject.staff", Operator: OpEquals, Value: true}}},
+		{ID: "blocked", Effect: EffectDeny, Actions: []string{"*"}, Conditions: []Condition{{Attribute: "subject.blocked", Operator: OpEquals, Value: true}}},
+		{ID: "no-write", Effect: EffectDeny, Actions: []string{"users:write"}},
+	}
+
+	tests := []struct {
+		name     string
+		req      Request
+		effect   string
+		policyID string
+	}{
+		{
+			"concessão por região",
+			Request{Action: "users:read", Subject: Attributes{"groups": []string{"support"}, "region": "south"}, Resource: Attributes{"region": "south"}},
+			EffectAllow, "support-region",
+		},
+		{
+			"outra região",
+			Request{Action: "users:read", Subject: Attributes{"groups": []string{"support"}, "region": "south"}, Resource: Attributes{"region": "north"}},
+			NotApplicable, "",
+		},
+		{
+			"primeira concessão aplicável",
+			Request{Action: "users:read", Subject: Attributes{"groups": []string{"support"}, "region": "south", "staff": true}, Resource: Attributes{"region": "south"}},
+			EffectAllow, "support-region",
+		},
+		{
+			"negação prevalece",
+			Request{Action: "users:read", Subject: Attributes{"groups": []string{"support"}, "region": "south", "blocked": true}, Resource: Attributes{"region": "south"}},
+			EffectDeny, "blocked",
+		},
+		{
+			"negação sem ação implícita",
+			Request{Action: "users:read", Subject: Attributes{"staff": true}},
+			EffectAllow, "all-read",
+		},
+		{
+			"negação sem condições",
+			Request{Action: "users:write", Subject: Attributes{"staff": true}},
+			EffectDeny, "no-write",
+		},
+		{
+			"nenhuma política",
+			Request{Action: "groups:write"},
+			NotApplicable, "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(policies, tt.req)
+			if got.Effect != tt.effect || got.PolicyID != tt.policyID {
+				t.Errorf("Evaluate() = %+v, esperado {Effect:%s PolicyID:%s}", got, tt.effect, tt.policyID)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		valid  bool
+	}{
+		{"válida", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "subject.region", Operator: OpEquals, Value: "south"}}}, true},
+		{"ação com curinga", Policy{Effect: EffectDeny, Actions: []string{"*"}}, true},
+		{"efeito inválido", Policy{Effect: "maybe", Actions: []string{"users:read"}}, false},
+		{"sem ações", Policy{Effect: EffectAllow}, false},
+		{"ação inválida", Policy{Effect: EffectAllow, Actions: []string{"users"}}, false},
+		{"prefixo desconhecido", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "user.region", Operator: OpExists}}}, false},
+		{"operador desconhecido", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "subject.region", Operator: "like", Value: "s"}}}, false},
+		{"sem valor", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "subject.region", Operator: OpEquals}}}, false},
+		{"value e value_from", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "subject.region", Operator: OpEquals, Value: "s", ValueFrom: "resource.region"}}}, false},
+		{"in sem lista", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "subject.region", Operator: OpIn, Value: "south"}}}, false},
+		{"horário inválido", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "context.time", Operator: OpTimeBetween, Value: []any{"9h", "18h"}}}}, false},
+		{"fuso inválido", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "context.time", Operator: OpTimeBetween, Value: []any{"09:00", "18:00"}, Timezone: "Mars/Base"}}}, false},
+		{"dia inválido", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "context.time", Operator: OpWeekdayIn, Value: []any{"monday"}}}}, false},
+		{"CIDR inválido", Policy{Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "context.ip", Operator: OpIPIn, Value: []any{"10.0.0.0/40"}}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.policy); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, esperado válida = %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-google/config"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// githubAPIURL é a base da API REST do GitHub
+const githubAPIURL = "https://api.github.com"
+
+// GitHubProvider autentica usuários pelo OAuth do GitHub, que não suporta OpenID Connect
+type GitHubProvider struct {
+	oauth2Config *oauth2.Config
+	apiURL       string
+}
+
+// NewGitHubProvider cria o provedor GitHub
+func NewGitHubProvider(cfg *config.Config) *GitHubProvider {
+	return &GitHubProvider{
+		oauth2Config: &oauth2.Config{
+			ClientID:     cfg.GitHubClientID,
+			ClientSecret: cfg.GitHubClientSecret,
+			RedirectURL:  cfg.GitHubRedirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+		apiURL: githubAPIURL,
+	}
+}
+
+// Name retorna o identificador do provedor
+func (p *GitHubProvider) Name() string {
+	return GitHub
+}
+
+// AuthCodeURL retorna a URL de autorização do GitHub com state e desafio PKCE
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	return authCodeURL(p.oauth2Config, req.State, req.CodeVerifier), nil
+}
+
+// githubUser representa a resposta de GET /user
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// githubEmail representa um item de GET /user/emails
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// Exchange troca o código de autorização e consulta o perfil do usuário na API do GitHub
+func (p *GitHubProvider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	token, err := exchangeCode(ctx, p.oauth2Config, code, req.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao trocar código por token: %w", err)
+	}
+
+	// O token segue no cabeçalho Authorization, nunca na query string
+	client := p.oauth2Config.Client(ctx, token)
+
+	var user githubUser
+	if err := p.getJSON(client, "/user", &user); err != nil {
+		return nil, err
+	}
+
+	var emails []githubEmail
+	if err := p.getJSON(client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: GitHub,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+		Picture:  user.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	if identity.Email == "" {
+		return nil, errors.New("o GitHub não retornou um e-mail primário para a conta")
+	}
+
+	return identity, nil
+}
+
+// getJSON executa um GET autenticado na API do GitHub e decodifica a resposta
+func (p *GitHubProvider) getJSON(client *http.Client, path string, target interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar requisição ao GitHub: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar o GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("resposta não-OK da API do GitHub: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta do GitHub: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"go-google/config"
+
+	"golang.org/x/oauth2"
+)
+
+// NewGoogleProvider cria o provedor Google, que valida o ID token contra o JWKS
+// configurado e exige e-mail verificado
+func NewGoogleProvider(cfg *config.Config) *OIDCProvider {
+	googleConfig := config.GetGoogleOAuthConfig(cfg)
+	issuers := cfg.GoogleIssuers()
+
+	authParams := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.ApprovalForce}
+	// O parâmetro hd é apenas uma dica de interface; a claim hd do ID token
+	// continua sendo validada pela política de login
+	switch len(cfg.AllowedHostedDomains) {
+	case 0:
+	case 1:
+		authParams = append(authParams, oauth2.SetAuthURLParam("hd", cfg.AllowedHostedDomains[0]))
+	default:
+		authParams = append(authParams, oauth2.SetAuthURLParam("hd", "*"))
+	}
+
+	return NewOIDCProvider(OIDCConfig{
+		Name:                 Google,
+		ClientID:             googleConfig.ClientID,
+		ClientSecret:         googleConfig.ClientSecret,
+		RedirectURL:          googleConfig.RedirectURL,
+		Issuer:               issuers[0],
+		ExtraIssuers:         issuers[1:],
+		AuthURL:              googleConfig.Endpoint.AuthURL,
+		TokenURL:             googleConfig.Endpoint.TokenURL,
+		JWKSURL:              cfg.GoogleJWKSURL,
+		Scopes:               googleConfig.Scopes,
+		AuthParams:           authParams,
+		RequireVerifiedEmail: true,
+	})
+}
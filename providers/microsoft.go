@@ -0,0 +1,17 @@
+package providers
+
+import (
+	"go-google/config"
+)
+
+// NewMicrosoftProvider cria o provedor Microsoft Entra ID para o tenant configurado.
+// Os endpoints são obtidos pela descoberta OIDC do issuer do tenant.
+func NewMicrosoftProvider(cfg *config.Config) *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		Name:         Microsoft,
+		ClientID:     cfg.MicrosoftClientID,
+		ClientSecret: cfg.MicrosoftClientSecret,
+		RedirectURL:  cfg.MicrosoftRedirectURL,
+		Issuer:       "https://login.microsoftonline.com/" + cfg.MicrosoftTenantID + "/v2.0",
+	})
+}
@@ -0,0 +1,138 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"go-google/oidc"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// OIDCConfig descreve um provedor OpenID Connect genérico. Os endpoints
+// podem ser omitidos para serem obtidos pela descoberta a partir do issuer.
+type OIDCConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Issuer       string
+	ExtraIssuers []string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	Scopes       []string
+	// AuthParams são parâmetros adicionais enviados na URL de autorização
+	AuthParams []oauth2.AuthCodeOption
+	// RequireVerifiedEmail rejeita identidades sem email_verified=true
+	RequireVerifiedEmail bool
+}
+
+// OIDCProvider autentica usuários validando o ID token emitido por um issuer OpenID Connect
+type OIDCProvider struct {
+	cfg OIDCConfig
+
+	mu           sync.Mutex
+	oauth2Config *oauth2.Config
+	verifier     *oidc.Verifier
+}
+
+// NewOIDCProvider cria um provedor OpenID Connect genérico
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{cfg: cfg}
+}
+
+// Name retorna o identificador do provedor
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL retorna a URL de autorização com state, nonce e desafio PKCE
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	oauth2Config, _, err := p.setup(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	opts := append([]oauth2.AuthCodeOption{oauth2.SetAuthURLParam("nonce", req.Nonce)}, p.cfg.AuthParams...)
+	return authCodeURL(oauth2Config, req.State, req.CodeVerifier, opts...), nil
+}
+
+// Exchange troca o código de autorização e valida o ID token retornado
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	oauth2Config, verifier, err := p.setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, oauth2Config, code, req.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao trocar código por token: %w", err)
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	claims, err := verifier.Verify(ctx, rawIDToken, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.cfg.RequireVerifiedEmail && !claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return &Identity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		HostedDomain:  claims.HostedDomain,
+	}, nil
+}
+
+// setup monta a configuração OAuth e o verificador, executando a descoberta
+// OIDC na primeira utilização quando os endpoints não foram configurados
+func (p *OIDCProvider) setup(ctx context.Context) (*oauth2.Config, *oidc.Verifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2Config != nil {
+		return p.oauth2Config, p.verifier, nil
+	}
+
+	authURL, tokenURL, jwksURL := p.cfg.AuthURL, p.cfg.TokenURL, p.cfg.JWKSURL
+	if authURL == "" || tokenURL == "" || jwksURL == "" {
+		metadata, err := oidc.Discover(ctx, p.cfg.Issuer)
+		if err != nil {
+			return nil, nil, err
+		}
+		if authURL == "" {
+			authURL = metadata.AuthorizationEndpoint
+		}
+		if tokenURL == "" {
+			tokenURL = metadata.TokenEndpoint
+		}
+		if jwksURL == "" {
+			jwksURL = metadata.JWKSURI
+		}
+	}
+
+	p.oauth2Config = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
+		},
+	}
+	issuers := append([]string{p.cfg.Issuer}, p.cfg.ExtraIssuers...)
+	p.verifier = oidc.NewVerifier(oidc.NewRemoteKeySet(jwksURL), p.cfg.ClientID, issuers...)
+
+	return p.oauth2Config, p.verifier, nil
+}
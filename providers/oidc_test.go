@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-google/oidc"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeOIDCServer simula os endpoints de token e JWKS de um provedor OIDC,
+// emitindo um ID token com o email_verified informado
+func fakeOIDCServer(t *testing.T, emailVerified bool) *httptest.Server {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave RSA: %v", err)
+	}
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{{
+			KeyID:   "key-1",
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            server.URL,
+			"aud":            "client-id",
+			"sub":            "user-123",
+			"email":          "ana@example.com",
+			"email_verified": emailVerified,
+			"nonce":          "nonce-value",
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "key-1"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fake-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCExchangeEmailVerified(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified bool
+		require       bool
+		wantErr       error
+	}{
+		{name: "e-mail verificado", emailVerified: true, require: true},
+		{name: "e-mail não verificado com exigência", emailVerified: false, require: true, wantErr: ErrEmailNotVerified},
+		{name: "e-mail não verificado sem exigência", emailVerified: false, require: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeOIDCServer(t, tt.emailVerified)
+			provider := NewOIDCProvider(OIDCConfig{
+				Name:                 "oidc",
+				ClientID:             "client-id",
+				ClientSecret:         "client-secret",
+				RedirectURL:          "http://localhost:8080/auth/callback",
+				Issuer:               server.URL,
+				AuthURL:              server.URL + "/authorize",
+				TokenURL:             server.URL + "/token",
+				JWKSURL:              server.URL + "/jwks",
+				RequireVerifiedEmail: tt.require,
+			})
+
+			identity, err := provider.Exchange(context.Background(), "valid-code", AuthRequest{Nonce: "nonce-value"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("erro = %v, esperado %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if identity.EmailVerified != tt.emailVerified {
+				t.Errorf("EmailVerified = %v, esperado %v", identity.EmailVerified, tt.emailVerified)
+			}
+		})
+	}
+}
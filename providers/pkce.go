@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+)
+
+// authCodeURL monta a URL de autorização, incluindo o desafio PKCE (S256)
+// quando um code_verifier foi gerado para o login
+func authCodeURL(oauthConfig *oauth2.Config, state, codeVerifier string, extra ...oauth2.AuthCodeOption) string {
+	opts := append([]oauth2.AuthCodeOption{}, extra...)
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.S256ChallengeOption(codeVerifier))
+	}
+	return oauthConfig.AuthCodeURL(state, opts...)
+}
+
+// exchangeCode troca o código de autorização por tokens, enviando o
+// code_verifier persistido junto ao state quando o PKCE está em uso
+func exchangeCode(ctx context.Context, oauthConfig *oauth2.Config, code, codeVerifier string) (*oauth2.Token, error) {
+	var opts []oauth2.AuthCodeOption
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(codeVerifier))
+	}
+	return oauthConfig.Exchange(ctx, code, opts...)
+}
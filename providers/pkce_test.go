@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeTokenEndpoint simula o endpoint de token do provedor, validando o
+// code_verifier recebido contra o desafio esperado
+func fakeTokenEndpoint(t *testing.T, expectedChallenge string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid_request", http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("code") != "valid-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		verifier := r.PostForm.Get("code_verifier")
+		if expectedChallenge == "" {
+			if verifier != "" {
+				http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+				return
+			}
+		} else {
+			sum := sha256.Sum256([]byte(verifier))
+			if base64.RawURLEncoding.EncodeToString(sum[:]) != expectedChallenge {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fake-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+}
+
+func testOAuthConfig(tokenURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:8080/auth/callback",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://provider.example/authorize",
+			TokenURL: tokenURL,
+		},
+	}
+}
+
+func TestAuthCodeURLIncludesS256Challenge(t *testing.T) {
+	verifier := oauth2.GenerateVerifier()
+	authURL := authCodeURL(testOAuthConfig("http://unused"), "state-value", verifier)
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("URL de autorização inválida: %v", err)
+	}
+	query := parsed.Query()
+
+	if got := query.Get("code_challenge_method"); got != "S256" {
+		t.Errorf("code_challenge_method = %q, esperado S256", got)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if got, want := query.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(sum[:]); got != want {
+		t.Errorf("code_challenge = %q, esperado %q", got, want)
+	}
+	if got := query.Get("state"); got != "state-value" {
+		t.Errorf("state = %q, esperado state-value", got)
+	}
+	if query.Get("code_verifier") != "" {
+		t.Error("o code_verifier não deve ser enviado na URL de autorização")
+	}
+}
+
+func TestAuthCodeURLWithoutVerifierOmitsChallenge(t *testing.T) {
+	authURL := authCodeURL(testOAuthConfig("http://unused"), "state-value", "")
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("URL de autorização inválida: %v", err)
+	}
+	if parsed.Query().Has("code_challenge") || parsed.Query().Has("code_challenge_method") {
+		t.Errorf("desafio PKCE inesperado na URL: %s", authURL)
+	}
+}
+
+func TestExchangeCodeSendsVerifier(t *testing.T) {
+	verifier := oauth2.GenerateVerifier()
+	oauthConfig := testOAuthConfig("")
+
+	authURL, err := url.Parse(authCodeURL(oauthConfig, "state-value", verifier))
+	if err != nil {
+		t.Fatalf("URL de autorização inválida: %v", err)
+	}
+
+	server := fakeTokenEndpoint(t, authURL.Query().Get("code_challenge"))
+	defer server.Close()
+	oauthConfig.Endpoint.TokenURL = server.URL
+
+	token, err := exchangeCode(context.Background(), oauthConfig, "valid-code", verifier)
+	if err != nil {
+		t.Fatalf("troca de código falhou: %v", err)
+	}
+	if token.AccessToken != "fake-access-token" {
+		t.Errorf("access token = %q, esperado fake-access-token", token.AccessToken)
+	}
+}
+
+func TestExchangeCodeRejectsWrongVerifier(t *testing.T) {
+	oauthConfig := testOAuthConfig("")
+
+	authURL, err := url.Parse(authCodeURL(oauthConfig, "state-value", oauth2.GenerateVerifier()))
+	if err != nil {
+		t.Fatalf("URL de autorização inválida: %v", err)
+	}
+
+	server := fakeTokenEndpoint(t, authURL.Query().Get("code_challenge"))
+	defer server.Close()
+	oauthConfig.Endpoint.TokenURL = server.URL
+
+	if _, err := exchangeCode(context.Background(), oauthConfig, "valid-code", oauth2.GenerateVerifier()); err == nil {
+		t.Fatal("esperado erro ao trocar o código com um code_verifier diferente")
+	}
+}
+
+func TestExchangeCodeWithoutPKCE(t *testing.T) {
+	server := fakeTokenEndpoint(t, "")
+	defer server.Close()
+
+	if _, err := exchangeCode(context.Background(), testOAuthConfig(server.URL), "valid-code", ""); err != nil {
+		t.Fatalf("troca de código sem PKCE falhou: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"context"
+	"errors"
+)
+
+// Nomes dos provedores de identidade embutidos
+const (
+	Google    = "google"
+	GitHub    = "github"
+	Microsoft = "microsoft"
+)
+
+var (
+	// ErrUnknownProvider indica que o provedor solicitado não está registrado
+	ErrUnknownProvider = errors.New("provedor de identidade desconhecido")
+	// ErrEmailNotVerified indica que o provedor não confirmou a posse do e-mail
+	ErrEmailNotVerified = errors.New("e-mail não verificado pelo provedor de identidade")
+)
+
+// Identity representa a identidade externa autenticada por um provedor
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+	HostedDomain  string
+}
+
+// AuthRequest reúne os parâmetros emitidos para um login específico
+type AuthRequest struct {
+	State        string
+	CodeVerifier string
+	Nonce        string
+}
+
+// IdentityProvider é implementado por cada provedor externo capaz de autenticar usuários
+type IdentityProvider interface {
+	// Name retorna o identificador do provedor usado nas rotas e nas identidades vinculadas
+	Name() string
+	// AuthCodeURL retorna a URL de autorização para onde o navegador deve ser redirecionado
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// Exchange troca o código de autorização e retorna a identidade verificada do usuário
+	Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error)
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"go-google/config"
+	"sort"
+	"strings"
+)
+
+// Registry mantém os provedores de identidade habilitados
+type Registry struct {
+	providers map[string]IdentityProvider
+}
+
+// NewRegistry cria um registro de provedores vazio
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]IdentityProvider),
+	}
+}
+
+// Register adiciona um provedor ao registro, substituindo outro com o mesmo nome
+func (r *Registry) Register(provider IdentityProvider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Get busca um provedor pelo nome
+func (r *Registry) Get(name string) (IdentityProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Names lista os nomes dos provedores registrados em ordem alfabética
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromConfig registra os provedores que possuem client ID configurado
+func FromConfig(cfg *config.Config) *Registry {
+	registry := NewRegistry()
+
+	if cfg.GoogleClientID != "" {
+		registry.Register(NewGoogleProvider(cfg))
+	}
+	if cfg.GitHubClientID != "" {
+		registry.Register(NewGitHubProvider(cfg))
+	}
+	// O issuer do Entra ID depende do tenant, por isso ele é obrigatório
+	if cfg.MicrosoftClientID != "" && cfg.MicrosoftTenantID != "" {
+		registry.Register(NewMicrosoftProvider(cfg))
+	}
+	if cfg.OIDCClientID != "" {
+		registry.Register(NewOIDCProvider(OIDCConfig{
+			Name:                 cfg.OIDCProviderName,
+			ClientID:             cfg.OIDCClientID,
+			ClientSecret:         cfg.OIDCClientSecret,
+			RedirectURL:          cfg.OIDCRedirectURL,
+			Issuer:               cfg.OIDCIssuer,
+			Scopes:               strings.Fields(cfg.OIDCScopes),
+			RequireVerifiedEmail: cfg.OIDCRequireVerifiedEmail,
+		}))
+	}
+
+	return registry
+}
@@ -0,0 +1,264 @@
+// Package rebac implementa a autorização baseada em relacionamentos, no estilo
+// do Zanzibar.
+//
+// As permissões são tuplas objeto#relação@sujeito (folder:reports#viewer@user:alice),
+// em que o sujeito pode ser um userset (folder:reports#viewer@group:eng#member,
+// os membros do grupo eng). O schema define as relações de cada namespace e as
+// regras que as compõem (userset rewrites):
+//
+//   - this: as tuplas gravadas com a própria relação
+//   - computed_userset: os sujeitos de outra relação do mesmo objeto (owner ⊂ viewer)
+//   - tuple_to_userset: os sujeitos de uma relação dos objetos apontados por
+//     outra relação (viewer da pasta pai ⊂ viewer do documento)
+package rebac
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MaxDepth limita a profundidade das verificações, que seguem usersets e regras recursivamente
+const MaxDepth = 32
+
+var (
+	// ErrUnknownRelation indica uma relação ou namespace fora do schema
+	ErrUnknownRelation = errors.New("relação não definida no schema")
+	// ErrDepthExceeded indica que a verificação ultrapassou MaxDepth
+	ErrDepthExceeded = errors.New("profundidade máxima da verificação de relações excedida")
+)
+
+// Reader fornece as tuplas armazenadas ou projetadas
+type Reader interface {
+	// ReadTuples retorna as tuplas do objeto com a relação
+	ReadTuples(object Object, relation string) ([]Tuple, error)
+	// ListObjectIDs retorna os IDs dos objetos do namespace que têm tuplas
+	ListObjectIDs(namespace string) ([]string, error)
+}
+
+// Engine avalia as relações de acordo com o schema
+type Engine struct {
+	schema *Schema
+	reader Reader
+}
+
+// NewEngine cria um avaliador de relações
+func NewEngine(schema *Schema, reader Reader) *Engine {
+	return &Engine{
+		schema: schema,
+		reader: reader,
+	}
+}
+
+// Schema retorna o schema usado pelo avaliador
+func (e *Engine) Schema() *Schema {
+	return e.schema
+}
+
+// Tree é a árvore de sujeitos de uma relação, resultado de Expand. Subjects traz
+// os sujeitos das tuplas diretas e Children as relações que compõem esta.
+type Tree struct {
+	Object   string   `json:"object"`
+	Relation string   `json:"relation"`
+	Subjects []string `json:"subjects,omitempty"`
+	Children []*Tree  `json:"children,omitempty"`
+}
+
+// Check indica se o sujeito tem a relação com o objeto, diretamente ou pelas regras do schema
+func (e *Engine) Check(object Object, relation string, subject Subject) (bool, error) {
+	return e.check(object, relation, subject, map[string]bool{}, 0)
+}
+
+// Expand monta a árvore de sujeitos que têm a relação com o objeto
+func (e *Engine) Expand(object Object, relation string) (*Tree, error) {
+	return e.expand(object, relation, map[string]bool{}, 0)
+}
+
+// ListObjects retorna, em ordem, os IDs dos objetos do namespace com os quais o
+// sujeito tem a relação. Os candidatos são os objetos que têm alguma tupla.
+func (e *Engine) ListObjects(namespace, relation string, subject Subject) ([]string, error) {
+	if _, ok := e.schema.Relation(namespace, relation); !ok {
+		return nil, fmt.Errorf("%w: %s#%s", ErrUnknownRelation, namespace, relation)
+	}
+
+	ids, err := e.reader.ListObjectIDs(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []string{}
+	for _, id := range ids {
+		allowed, err := e.Check(Object{Namespace: namespace, ID: id}, relation, subject)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			objects = append(objects, id)
+		}
+	}
+	sort.Strings(objects)
+	return objects, nil
+}
+
+// ValidateTuple verifica se a tupla pode ser gravada: a relação deve aceitar
+// tuplas diretas e o sujeito deve existir no schema
+func (e *Engine) ValidateTuple(tuple Tuple) error {
+	definition, ok := e.schema.Relation(tuple.Object.Namespace, tuple.Relation)
+	if !ok {
+		return fmt.Errorf("%w: %s#%s", ErrUnknownRelation, tuple.Object.Namespace, tuple.Relation)
+	}
+	if !definition.AllowsDirect() {
+		return fmt.Errorf("%w: %s#%s não aceita tuplas diretas", ErrUnknownRelation, tuple.Object.Namespace, tuple.Relation)
+	}
+
+	subject := tuple.Subject
+	if !e.schema.HasNamespace(subject.Object.Namespace) {
+		return fmt.Errorf("%w: namespace %s", ErrUnknownRelation, subject.Object.Namespace)
+	}
+	if subject.IsUserset() {
+		if _, ok := e.schema.Relation(subject.Object.Namespace, subject.Relation); !ok {
+			return fmt.Errorf("%w: %s#%s", ErrUnknownRelation, subject.Object.Namespace, subject.Relation)
+		}
+	}
+	return nil
+}
+
+// check avalia a relação seguindo as regras do schema. visiting guarda as
+// relações do caminho atual para interromper ciclos.
+func (e *Engine) check(object Object, relation string, subject Subject, visiting map[string]bool, depth int) (bool, error) {
+	if depth > MaxDepth {
+		return false, ErrDepthExceeded
+	}
+	definition, ok := e.schema.Relation(object.Namespace, relation)
+	if !ok {
+		return false, fmt.Errorf("%w: %s#%s", ErrUnknownRelation, object.Namespace, relation)
+	}
+
+	key := object.String() + "#" + relation
+	if visiting[key] {
+		return false, nil
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	for _, rewrite := range definition.Rewrites() {
+		var allowed bool
+		var err error
+		switch {
+		case rewrite.This:
+			allowed, err = e.checkDirect(object, relation, subject, visiting, depth)
+		case rewrite.ComputedUserset != "":
+			allowed, err = e.check(object, rewrite.ComputedUserset, subject, visiting, depth+1)
+		case rewrite.TupleToUserset != nil:
+			allowed, err = e.checkTupleToUserset(object, *rewrite.TupleToUserset, subject, visiting, depth)
+		}
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
+
+// checkDirect procura o sujeito nas tuplas da relação e nos usersets referenciados por elas
+func (e *Engine) checkDirect(object Object, relation string, subject Subject, visiting map[string]bool, depth int) (bool, error) {
+	tuples, err := e.reader.ReadTuples(object, relation)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tuple := range tuples {
+		if tuple.Subject == subject {
+			return true, nil
+		}
+		if tuple.Subject.IsUserset() {
+			allowed, err := e.check(tuple.Subject.Object, tuple.Subject.Relation, subject, visiting, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// checkTupleToUserset segue as tuplas do tupleset e avalia a relação computada nos objetos apontados
+func (e *Engine) checkTupleToUserset(object Object, rewrite TupleToUserset, subject Subject, visiting map[string]bool, depth int) (bool, error) {
+	tuples, err := e.reader.ReadTuples(object, rewrite.Tupleset)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tuple := range tuples {
+		target := tuple.Subject.Object
+		// Objetos de namespaces sem a relação computada não contribuem
+		if _, ok := e.schema.Relation(target.Namespace, rewrite.ComputedUserset); !ok {
+			continue
+		}
+		allowed, err := e.check(target, rewrite.ComputedUserset, subject, visiting, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
+
+// expand monta a árvore da relação seguindo as mesmas regras de check
+func (e *Engine) expand(object Object, relation string, visiting map[string]bool, depth int) (*Tree, error) {
+	if depth > MaxDepth {
+		return nil, ErrDepthExceeded
+	}
+	definition, ok := e.schema.Relation(object.Namespace, relation)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s#%s", ErrUnknownRelation, object.Namespace, relation)
+	}
+
+	tree := &Tree{Object: object.String(), Relation: relation}
+	key := object.String() + "#" + relation
+	if visiting[key] {
+		return tree, nil
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	for _, rewrite := range definition.Rewrites() {
+		switch {
+		case rewrite.This:
+			tuples, err := e.reader.ReadTuples(object, relation)
+			if err != nil {
+				return nil, err
+			}
+			for _, tuple := range tuples {
+				tree.Subjects = append(tree.Subjects, tuple.Subject.String())
+				if tuple.Subject.IsUserset() {
+					child, err := e.expand(tuple.Subject.Object, tuple.Subject.Relation, visiting, depth+1)
+					if err != nil {
+						return nil, err
+					}
+					tree.Children = append(tree.Children, child)
+				}
+			}
+		case rewrite.ComputedUserset != "":
+			child, err := e.expand(object, rewrite.ComputedUserset, visiting, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			tree.Children = append(tree.Children, child)
+		case rewrite.TupleToUserset != nil:
+			tuples, err := e.reader.ReadTuples(object, rewrite.TupleToUserset.Tupleset)
+			if err != nil {
+				return nil, err
+			}
+			for _, tuple := range tuples {
+				target := tuple.Subject.Object
+				if _, ok := e.schema.Relation(target.Namespace, rewrite.TupleToUserset.ComputedUserset); !ok {
+					continue
+				}
+				child, err := e.expand(target, rewrite.TupleToUserset.ComputedUserset, visiting, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				tree.Children = append(tree.Children, child)
+			}
+		}
+	}
+	return tree, nil
+}
@@ -0,0 +1,251 @@
+package rebac
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// memoryReader guarda as tuplas em memória para os testes
+type memoryReader []Tuple
+
+func (m memoryReader) ReadTuples(object Object, relation string) ([]Tuple, error) {
+	var tuples []Tuple
+	for _, tuple := range m {
+		if tuple.Object == object && tuple.Relation == relation {
+			tuples = append(tuples, tuple)
+		}
+	}
+	return tuples, nil
+}
+
+func (m memoryReader) ListObjectIDs(namespace string) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, tuple := range m {
+		if tuple.Object.Namespace == namespace && !seen[tuple.Object.ID] {
+			seen[tuple.Object.ID] = true
+			ids = append(ids, tuple.Object.ID)
+		}
+	}
+	return ids, nil
+}
+
+const testSchema = `{
+	"namespaces": {
+		"folder": {
+			"relations": {
+				"parent": {},
+				"owner": {},
+				"viewer": {"union": [
+					{"this": true},
+					{"computed_userset": "owner"},
+					{"tuple_to_userset": {"tupleset": "parent", "computed_userset": "viewer"}}
+				]}
+			}
+		},
+		"doc": {
+			"relations": {
+				"parent": {},
+				"viewer": {"union": [
+					{"this": true},
+					{"tuple_to_userset": {"tupleset": "parent", "computed_userset": "viewer"}}
+				]}
+			}
+		}
+	}
+}`
+
+func mustTuples(t *testing.T, values ...string) memoryReader {
+	t.Helper()
+	var tuples memoryReader
+	for _, value := range values {
+		tuple, err := ParseTuple(value)
+		if err != nil {
+			t.Fatalf("ParseTuple(%q): %v", value, err)
+		}
+		tuples = append(tuples, tuple)
+	}
+	return tuples
+}
+
+func newTestEngine(t *testing.T, tuples memoryReader) *Engine {
+	t.Helper()
+	schema, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	return NewEngine(schema, tuples)
+}
+
+func TestCheck(t *testing.T) {
+	engine := newTestEngine(t, mustTuples(t,
+		"group:eng#member@user:alice",
+		"group:platform#member@user:bob",
+		"group:eng#member@group:platform#member",
+		"folder:root#owner@user:carol",
+		"folder:reports#parent@folder:root",
+		"folder:reports#viewer@group:eng#member",
+		"doc:q1#parent@folder:reports",
+		"doc:q1#viewer@user:dave",
+		// Ciclo entre pastas não deve travar a verificação
+		"folder:a#parent@folder:b",
+		"folder:b#parent@folder:a",
+	))
+
+	tests := []struct {
+		name     string
+		object   string
+		relation string
+		subject  string
+		want     bool
+	}{
+		{"tupla direta", "doc:q1", "viewer", "user:dave", true},
+		{"membro do grupo via userset", "folder:reports", "viewer", "user:alice", true},
+		{"membro do subgrupo", "folder:reports", "viewer", "user:bob", true},
+		{"computed_userset", "folder:root", "viewer", "user:carol", true},
+		{"tuple_to_userset", "folder:reports", "viewer", "user:carol", true},
+		{"tuple_to_userset encadeado", "doc:q1", "viewer", "user:bob", true},
+		{"userset como sujeito", "folder:reports", "viewer", "group:eng#member", true},
+		{"sem relação", "folder:root", "viewer", "user:alice", false},
+		{"owner não herda viewer", "folder:reports", "owner", "user:alice", false},
+		{"ciclo", "folder:a", "viewer", "user:alice", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, _ := ParseObject(tt.object)
+			subject, _ := ParseSubject(tt.subject)
+			got, err := engine.Check(object, tt.relation, subject)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Check(%s#%s@%s) = %v, esperado %v", tt.object, tt.relation, tt.subject, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckUnknownRelation(t *testing.T) {
+	engine := newTestEngine(t, nil)
+	_, err := engine.Check(Object{Namespace: "folder", ID: "x"}, "editor", Subject{Object: Object{Namespace: "user", ID: "alice"}})
+	if !errors.Is(err, ErrUnknownRelation) {
+		t.Errorf("Check com relação desconhecida = %v, esperado ErrUnknownRelation", err)
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	engine := newTestEngine(t, mustTuples(t,
+		"group:eng#member@user:alice",
+		"folder:root#owner@user:carol",
+		"folder:reports#parent@folder:root",
+		"folder:reports#viewer@group:eng#member",
+		"folder:other#viewer@user:dave",
+	))
+
+	tests := []struct {
+		subject string
+		want    []string
+	}{
+		{"user:alice", []string{"reports"}},
+		{"user:carol", []string{"reports", "root"}},
+		{"user:erin", []string{}},
+	}
+
+	for _, tt := range tests {
+		subject, _ := ParseSubject(tt.subject)
+		got, err := engine.ListObjects("folder", "viewer", subject)
+		if err != nil {
+			t.Fatalf("ListObjects: %v", err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListObjects(folder, viewer, %s) = %v, esperado %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	engine := newTestEngine(t, mustTuples(t,
+		"group:eng#member@user:alice",
+		"folder:root#owner@user:carol",
+		"folder:reports#parent@folder:root",
+		"folder:reports#viewer@group:eng#member",
+	))
+
+	tree, err := engine.Expand(Object{Namespace: "folder", ID: "reports"}, "viewer")
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+
+	if !reflect.DeepEqual(tree.Subjects, []string{"group:eng#member"}) {
+		t.Errorf("Subjects = %v", tree.Subjects)
+	}
+	// group:eng#member, folder:reports#owner e folder:root#viewer
+	if len(tree.Children) != 3 {
+		t.Fatalf("Children = %d, esperado 3", len(tree.Children))
+	}
+	if got := tree.Children[0].Subjects; !reflect.DeepEqual(got, []string{"user:alice"}) {
+		t.Errorf("membros do grupo = %v", got)
+	}
+	root := tree.Children[2]
+	if root.Object != "folder:root" || root.Relation != "viewer" || len(root.Children) != 1 {
+		t.Fatalf("árvore da pasta pai inesperada: %+v", root)
+	}
+	if got := root.Children[0].Subjects; !reflect.DeepEqual(got, []string{"user:carol"}) {
+		t.Errorf("owners da pasta pai = %v", got)
+	}
+}
+
+func TestParseTuple(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"doc:readme#viewer@user:alice", true},
+		{"doc:readme#viewer@group:eng#member", true},
+		{"doc:2024/q1.pdf#viewer@user:3f2c9a", true},
+		{"doc:readme#viewer", false},
+		{"doc#viewer@user:alice", false},
+		{"Doc:readme#viewer@user:alice", false},
+		{"doc:readme#viewer@user", false},
+		{"doc:readme#@user:alice", false},
+		{"doc:readme#viewer@group:eng#", false},
+	}
+
+	for _, tt := range tests {
+		tuple, err := ParseTuple(tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseTuple(%q) erro = %v, esperado válida = %v", tt.value, err, tt.valid)
+		}
+		if err == nil && tuple.String() != tt.value {
+			t.Errorf("ParseTuple(%q).String() = %q", tt.value, tuple.String())
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		valid  bool
+	}{
+		{"válido", testSchema, true},
+		{"vazio", `{}`, true},
+		{"computed_userset inexistente", `{"namespaces": {"doc": {"relations": {"viewer": {"union": [{"computed_userset": "owner"}]}}}}}`, false},
+		{"tupleset inexistente", `{"namespaces": {"doc": {"relations": {"viewer": {"union": [{"tuple_to_userset": {"tupleset": "parent", "computed_userset": "viewer"}}]}}}}}`, false},
+		{"regra vazia", `{"namespaces": {"doc": {"relations": {"viewer": {"union": [{}]}}}}}`, false},
+		{"regra dupla", `{"namespaces": {"doc": {"relations": {"owner": {}, "viewer": {"union": [{"this": true, "computed_userset": "owner"}]}}}}}`, false},
+		{"namespace embutido", `{"namespaces": {"group": {"relations": {"admin": {}}}}}`, false},
+		{"nome inválido", `{"namespaces": {"Doc": {"relations": {}}}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchema([]byte(tt.schema))
+			if (err == nil) != tt.valid {
+				t.Errorf("ParseSchema() = %v, esperado válido = %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package rebac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Namespaces e relação embutidos. As associações de models.Group são projetadas
+// como tuplas group:<id>#member@user:<id> e, para os subgrupos,
+// group:<pai>#member@group:<filho>#member.
+const (
+	UserNamespace  = "user"
+	GroupNamespace = "group"
+	MemberRelation = "member"
+)
+
+// Schema define os namespaces e as relações aceitos nas tuplas
+type Schema struct {
+	Namespaces map[string]Namespace `json:"namespaces"`
+}
+
+// Namespace define as relações de um tipo de objeto
+type Namespace struct {
+	Relations map[string]Relation `json:"relations"`
+}
+
+// Relation é a união das regras (userset rewrites) que formam a relação. Sem
+// regras, a relação é formada apenas pelas tuplas gravadas diretamente.
+type Relation struct {
+	Union []Rewrite `json:"union,omitempty"`
+}
+
+// Rewrite é uma regra da relação; apenas um dos campos deve ser informado
+type Rewrite struct {
+	// This inclui os sujeitos das tuplas gravadas com a própria relação
+	This bool `json:"this,omitempty"`
+	// ComputedUserset inclui os sujeitos de outra relação do mesmo objeto
+	// (todo owner também é viewer)
+	ComputedUserset string `json:"computed_userset,omitempty"`
+	// TupleToUserset segue as tuplas de uma relação até outros objetos e inclui
+	// os sujeitos de uma relação deles (viewer da pasta pai também é viewer)
+	TupleToUserset *TupleToUserset `json:"tuple_to_userset,omitempty"`
+}
+
+// TupleToUserset segue os objetos da relação Tupleset e inclui os sujeitos da
+// relação ComputedUserset desses objetos
+type TupleToUserset struct {
+	Tupleset        string `json:"tupleset"`
+	ComputedUserset string `json:"computed_userset"`
+}
+
+// directRewrites é a regra das relações sem união declarada
+var directRewrites = []Rewrite{{This: true}}
+
+// Rewrites retorna as regras da relação
+func (r Relation) Rewrites() []Rewrite {
+	if len(r.Union) == 0 {
+		return directRewrites
+	}
+	return r.Union
+}
+
+// AllowsDirect indica se a relação aceita tuplas gravadas diretamente
+func (r Relation) AllowsDirect() bool {
+	for _, rewrite := range r.Rewrites() {
+		if rewrite.This {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSchema retorna o schema com apenas os namespaces embutidos
+func DefaultSchema() *Schema {
+	schema := &Schema{Namespaces: map[string]Namespace{}}
+	schema.addBuiltins()
+	return schema
+}
+
+// LoadSchema lê o schema de um arquivo JSON e acrescenta os namespaces
+// embutidos. Sem arquivo, retorna o schema padrão.
+func LoadSchema(path string) (*Schema, error) {
+	if path == "" {
+		return DefaultSchema(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o schema de relações: %w", err)
+	}
+	return ParseSchema(data)
+}
+
+// ParseSchema interpreta e valida um schema em JSON, acrescentando os
+// namespaces embutidos, que não podem ser redefinidos
+func ParseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("schema de relações inválido: %w", err)
+	}
+	if schema.Namespaces == nil {
+		schema.Namespaces = map[string]Namespace{}
+	}
+	for _, builtin := range []string{UserNamespace, GroupNamespace} {
+		if _, exists := schema.Namespaces[builtin]; exists {
+			return nil, fmt.Errorf("schema de relações inválido: o namespace %s é embutido e não pode ser redefinido", builtin)
+		}
+	}
+
+	schema.addBuiltins()
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("schema de relações inválido: %w", err)
+	}
+	return &schema, nil
+}
+
+// Validate verifica os nomes e as referências das regras de cada relação
+func (s *Schema) Validate() error {
+	for name, namespace := range s.Namespaces {
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("nome de namespace inválido %q", name)
+		}
+		for relationName, relation := range namespace.Relations {
+			if !namePattern.MatchString(relationName) {
+				return fmt.Errorf("%s: nome de relação inválido %q", name, relationName)
+			}
+			for _, rewrite := range relation.Union {
+				if err := namespace.validateRewrite(rewrite); err != nil {
+					return fmt.Errorf("%s#%s: %w", name, relationName, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Relation busca a definição de uma relação
+func (s *Schema) Relation(namespace, relation string) (Relation, bool) {
+	definition, ok := s.Namespaces[namespace].Relations[relation]
+	return definition, ok
+}
+
+// HasNamespace indica se o namespace está definido
+func (s *Schema) HasNamespace(namespace string) bool {
+	_, ok := s.Namespaces[namespace]
+	return ok
+}
+
+// NamespaceNames retorna os nomes dos namespaces em ordem alfabética
+func (s *Schema) NamespaceNames() []string {
+	names := make([]string, 0, len(s.Namespaces))
+	for name := range s.Namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateRewrite exige exatamente um tipo de regra e relações existentes no namespace
+func (n Namespace) validateRewrite(rewrite Rewrite) error {
+	kinds := 0
+	if rewrite.This {
+		kinds++
+	}
+	if rewrite.ComputedUserset != "" {
+		kinds++
+		if _, ok := n.Relations[rewrite.ComputedUserset]; !ok {
+			return fmt.Errorf("computed_userset referencia a relação inexistente %q", rewrite.ComputedUserset)
+		}
+	}
+	if rewrite.TupleToUserset != nil {
+		kinds++
+		if _, ok := n.Relations[rewrite.TupleToUserset.Tupleset]; !ok {
+			return fmt.Errorf("tuple_to_userset referencia a relação inexistente %q", rewrite.TupleToUserset.Tupleset)
+		}
+		if !namePattern.MatchString(rewrite.TupleToUserset.ComputedUserset) {
+			return fmt.Errorf("tuple_to_userset com computed_userset inválido %q", rewrite.TupleToUserset.ComputedUserset)
+		}
+	}
+	if kinds != 1 {
+		return errors.New("cada regra deve ter exatamente um de this, computed_userset ou tuple_to_userset")
+	}
+	return nil
+}
+
+// addBuiltins acrescenta os namespaces de usuários e grupos
+func (s *Schema) addBuiltins() {
+	s.Namespaces[UserNamespace] = Namespace{Relations: map[string]Relation{}}
+	s.Namespaces[GroupNamespace] = Namespace{Relations: map[string]Relation{
+		MemberRelation: {},
+	}}
+}
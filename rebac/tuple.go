@@ -0,0 +1,118 @@
+package rebac
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// namePattern define os nomes aceitos para namespaces e relações
+	namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// idPattern define os IDs de objeto aceitos; # e @ separam as partes de uma tupla
+	idPattern = regexp.MustCompile(`^[A-Za-z0-9_.:/|=+-]+$`)
+)
+
+// Object identifica um objeto pelo namespace e ID (folder:reports)
+type Object struct {
+	Namespace string
+	ID        string
+}
+
+// String formata o objeto como namespace:id
+func (o Object) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// ParseObject interpreta um objeto no formato namespace:id
+func ParseObject(value string) (Object, error) {
+	namespace, id, ok := strings.Cut(value, ":")
+	if !ok || !namePattern.MatchString(namespace) || !idPattern.MatchString(id) {
+		return Object{}, fmt.Errorf("objeto inválido %q: use namespace:id", value)
+	}
+	return Object{Namespace: namespace, ID: id}, nil
+}
+
+// Subject é o sujeito de uma tupla: um objeto (user:alice) ou um userset, o
+// conjunto de sujeitos com uma relação em um objeto (group:eng#member)
+type Subject struct {
+	Object   Object
+	Relation string
+}
+
+// IsUserset indica se o sujeito é um userset
+func (s Subject) IsUserset() bool {
+	return s.Relation != ""
+}
+
+// String formata o sujeito como namespace:id ou namespace:id#relação
+func (s Subject) String() string {
+	if s.IsUserset() {
+		return s.Object.String() + "#" + s.Relation
+	}
+	return s.Object.String()
+}
+
+// ParseSubject interpreta um sujeito no formato namespace:id ou namespace:id#relação
+func ParseSubject(value string) (Subject, error) {
+	objectPart, relation, userset := strings.Cut(value, "#")
+	object, err := ParseObject(objectPart)
+	if err != nil {
+		return Subject{}, err
+	}
+	if userset && !namePattern.MatchString(relation) {
+		return Subject{}, fmt.Errorf("relação inválida no sujeito %q", value)
+	}
+	return Subject{Object: object, Relation: relation}, nil
+}
+
+// Tuple declara que o sujeito tem a relação com o objeto (objeto#relação@sujeito)
+type Tuple struct {
+	Object   Object
+	Relation string
+	Subject  Subject
+}
+
+// String formata a tupla como objeto#relação@sujeito
+func (t Tuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseTuple interpreta uma tupla no formato objeto#relação@sujeito
+func ParseTuple(value string) (Tuple, error) {
+	left, subjectPart, ok := strings.Cut(value, "@")
+	if !ok {
+		return Tuple{}, fmt.Errorf("tupla inválida %q: use objeto#relação@sujeito", value)
+	}
+	objectPart, relation, ok := strings.Cut(left, "#")
+	if !ok {
+		return Tuple{}, fmt.Errorf("tupla inválida %q: use objeto#relação@sujeito", value)
+	}
+	return NewTuple(objectPart, relation, subjectPart)
+}
+
+// NewTuple monta uma tupla a partir das partes em texto
+func NewTuple(object, relation, subject string) (Tuple, error) {
+	parsedObject, err := ParseObject(object)
+	if err != nil {
+		return Tuple{}, err
+	}
+	if !namePattern.MatchString(relation) {
+		return Tuple{}, fmt.Errorf("relação inválida %q", relation)
+	}
+	parsedSubject, err := ParseSubject(subject)
+	if err != nil {
+		return Tuple{}, err
+	}
+	return Tuple{Object: parsedObject, Relation: relation, Subject: parsedSubject}, nil
+}
+
+// MarshalJSON serializa a tupla com as partes em texto
+func (t Tuple) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"object":   t.Object.String(),
+		"relation": t.Relation,
+		"subject":  t.Subject.String(),
+	})
+}
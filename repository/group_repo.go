@@ -2,6 +2,7 @@ package repository
 
 import (
 	"go-google/models"
+	"go-google/rebac"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -19,20 +20,20 @@ func NewGroupRepository(db *gorm.DB) *GroupRepository {
 	}
 }
 
-// FindByID busca um grupo pelo ID
-func (r *GroupRepository) FindByID(id string) (*models.Group, error) {
+// FindByID busca um grupo da organização pelo ID
+func (r *GroupRepository) FindByID(orgID uuid.UUID, id string) (*models.Group, error) {
 	var group models.Group
-	result := r.db.Where("id = ?", id).Preload("Roles").First(&group)
+	result := r.db.Where("organization_id = ? AND id = ?", orgID, id).Preload("Roles").Preload("Parents").First(&group)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &group, nil
 }
 
-// FindByName busca um grupo pelo nome
-func (r *GroupRepository) FindByName(name string) (*models.Group, error) {
+// FindByName busca um grupo da organização pelo nome
+func (r *GroupRepository) FindByName(orgID uuid.UUID, name string) (*models.Group, error) {
 	var group models.Group
-	result := r.db.Where("name = ?", name).Preload("Roles").First(&group)
+	result := r.db.Where("organization_id = ? AND name = ?", orgID, name).Preload("Roles").Preload("Parents").First(&group)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -44,65 +45,201 @@ func (r *GroupRepository) Create(group *models.Group) error {
 	return r.db.Create(group).Error
 }
 
-// Update atualiza um grupo existente
+// Update atualiza os dados de um grupo existente, sem alterar membros, papéis e grupos pais
 func (r *GroupRepository) Update(group *models.Group) error {
-	return r.db.Save(group).Error
+	return r.db.Omit("Users", "Roles", "Parents").Save(group).Error
 }
 
-// ListAll lista todos os grupos
-func (r *GroupRepository) ListAll() ([]models.Group, error) {
+// SetParents substitui os grupos pais de um grupo e invalida as permissões em
+// cache dos membros dele e dos seus subgrupos
+func (r *GroupRepository) SetParents(group *models.Group, parents []models.Group) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Association("Parents").Replace(parents); err != nil {
+			return err
+		}
+		return bumpGroupMembers(tx, []uuid.UUID{group.ID})
+	})
+}
+
+// FindWithAncestors busca os grupos informados da organização e todos os
+// grupos que os contêm, direta ou indiretamente
+func (r *GroupRepository) FindWithAncestors(orgID uuid.UUID, ids []uuid.UUID) ([]models.Group, error) {
 	var groups []models.Group
-	if err := r.db.Preload("Roles").Find(&groups).Error; err != nil {
+	if len(ids) == 0 {
+		return groups, nil
+	}
+
+	err := r.db.Raw(`WITH RECURSIVE lineage(id) AS (
+			SELECT id FROM groups WHERE id IN ? AND organization_id = ?
+			UNION
+			SELECT group_parents.parent_id FROM group_parents JOIN lineage ON group_parents.group_id = lineage.id
+		)
+		SELECT * FROM groups WHERE id IN (SELECT id FROM lineage) ORDER BY name`, ids, orgID).Scan(&groups).Error
+	if err != nil {
 		return nil, err
 	}
 	return groups, nil
 }
 
-// AssignRoles atribui papéis a um grupo
-func (r *GroupRepository) AssignRoles(groupID string, roleIDs []string) error {
-	// Converter string IDs para UUIDs
-	var roles []models.Role
-	for _, id := range roleIDs {
-		roleID, err := uuid.Parse(id)
-		if err != nil {
-			continue // Ignorar IDs inválidos
+// FindGrantedRoleIDs retorna os papéis atribuídos aos grupos informados e aos
+// grupos que os contêm, ou seja, os papéis que um membro desses grupos tem
+func (r *GroupRepository) FindGrantedRoleIDs(orgID uuid.UUID, groupIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(groupIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Raw(`WITH RECURSIVE lineage(id) AS (
+			SELECT id FROM groups WHERE id IN ? AND organization_id = ?
+			UNION
+			SELECT group_parents.parent_id FROM group_parents JOIN lineage ON group_parents.group_id = lineage.id
+		)
+		SELECT DISTINCT role_id FROM group_roles WHERE group_id IN (SELECT id FROM lineage)`, groupIDs, orgID).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListAll lista todos os grupos da organização
+func (r *GroupRepository) ListAll(orgID uuid.UUID) ([]models.Group, error) {
+	var groups []models.Group
+	if err := r.db.Where("organization_id = ?", orgID).Preload("Roles").Preload("Parents").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// AssignRoles substitui os papéis de um grupo
+func (r *GroupRepository) AssignRoles(group *models.Group, roles []models.Role) error {
+	// Associar papéis ao grupo e invalidar as permissões em cache dos membros
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+		return bumpGroupMembers(tx, []uuid.UUID{group.ID})
+	})
+}
+
+// Delete exclui um grupo, removendo seus membros, papéis e as atribuições
+// que ele recebe ou que têm o grupo como recurso
+func (r *GroupRepository) Delete(group *models.Group) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// A versão é incrementada antes de remover os vínculos que localizam os membros
+		if err := bumpGroupMembers(tx, []uuid.UUID{group.ID}); err != nil {
+			return err
+		}
+		if err := tx.Model(group).Association("Users").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(group).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(group).Association("Parents").Clear(); err != nil {
+			return err
+		}
+		// Os subgrupos deixam de fazer parte deste grupo
+		if err := tx.Exec("DELETE FROM group_parents WHERE parent_id = ?", group.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM invitation_groups WHERE group_id = ?", group.ID).Error; err != nil {
+			return err
 		}
-		roles = append(roles, models.Role{ID: roleID})
+		if err := tx.Where("subject_type = ? AND subject_id = ?", models.SubjectGroup, group.ID).Delete(&models.RoleBinding{}).Error; err != nil {
+			return err
+		}
+		// As atribuições sobre o grupo como recurso não podem sobreviver a ele
+		if err := tx.Where("organization_id = ? AND resource_type = ? AND resource_id = ?", group.OrganizationID, models.ResourceTypeGroup, group.ID.String()).Delete(&models.RoleBinding{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subject_namespace = ? AND subject_id = ?", rebac.GroupNamespace, group.ID.String()).Delete(&models.RelationTuple{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+}
+
+// FindMemberIDs retorna os IDs dos usuários associados diretamente ao grupo da
+// organização e dos seus subgrupos diretos; um grupo de outra organização não
+// tem membros
+func (r *GroupRepository) FindMemberIDs(orgID, groupID uuid.UUID) ([]uuid.UUID, []uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Table("user_groups").Joins("JOIN groups ON groups.id = user_groups.group_id").
+		Where("user_groups.group_id = ? AND groups.organization_id = ?", groupID, orgID).
+		Pluck("user_groups.user_id", &userIDs).Error
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Buscar grupo
-	group, err := r.FindByID(groupID)
+	var childIDs []uuid.UUID
+	err = r.db.Table("group_parents").Joins("JOIN groups ON groups.id = group_parents.group_id").
+		Where("group_parents.parent_id = ? AND groups.organization_id = ?", groupID, orgID).
+		Pluck("group_parents.group_id", &childIDs).Error
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	return userIDs, childIDs, nil
+}
 
-	// Associar papéis ao grupo
-	return r.db.Model(group).Association("Roles").Replace(roles)
-}
-
-// FindOrCreateDefaultRoles encontra ou cria os papéis padrão
-func (r *GroupRepository) FindOrCreateDefaultRoles() (map[string]models.Role, error) {
-	defaultRoles := models.GetDefaultRoles()
-	roleMap := make(map[string]models.Role)
-
-	for _, defaultRole := range defaultRoles {
-		var role models.Role
-		result := r.db.Where("name = ?", defaultRole.Name).First(&role)
-		if result.Error != nil {
-			if result.Error == gorm.ErrRecordNotFound {
-				
-				// Criar papel se não existir
-				if err := r.db.Create(&defaultRole).Error; err != nil {
-					return nil, err
-				}
-				roleMap[defaultRole.Name] = defaultRole
-			} else {
-				return nil, result.Error
-			}
-		} else {
-			roleMap[role.Name] = role
+// ListIDs lista os IDs dos grupos da organização
+func (r *GroupRepository) ListIDs(orgID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&models.Group{}).Where("organization_id = ?", orgID).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListMembers lista os usuários que pertencem ao grupo
+func (r *GroupRepository) ListMembers(groupID uuid.UUID) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Joins("JOIN user_groups ON user_groups.user_id = users.id").
+		Where("user_groups.group_id = ?", groupID).Order("users.name").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// AddMember adiciona um usuário ao grupo sem alterar suas outras associações
+func (r *GroupRepository) AddMember(groupID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("INSERT INTO user_groups (user_id, group_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userID, groupID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
 		}
+		return bumpAuthzVersion(tx, "id = ?", userID)
+	})
+}
+
+// RemoveMember remove um usuário do grupo
+func (r *GroupRepository) RemoveMember(groupID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("DELETE FROM user_groups WHERE user_id = ? AND group_id = ?", userID, groupID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return bumpAuthzVersion(tx, "id = ?", userID)
+	})
+}
+
+// bumpGroupMembers incrementa a versão de autorização dos membros dos grupos
+// informados e dos seus subgrupos, que herdam os papéis dos grupos ancestrais
+func bumpGroupMembers(tx *gorm.DB, groupIDs []uuid.UUID) error {
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	var subtree []uuid.UUID
+	err := tx.Raw(`WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM groups WHERE id IN ?
+			UNION
+			SELECT group_parents.group_id FROM group_parents JOIN subtree ON group_parents.parent_id = subtree.id
+		)
+		SELECT id FROM subtree`, groupIDs).Scan(&subtree).Error
+	if err != nil {
+		return err
 	}
 
-	return roleMap, nil
-}
\ No newline at end of file
+	return bumpAuthzVersion(tx, "id IN (?)", tx.Table("user_groups").Select("user_id").Where("group_id IN ?", subtree))
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+
+	"gorm.io/gorm"
+)
+
+// IdentityRepository manipula operações de banco de dados relacionadas às identidades externas vinculadas
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository cria um novo repositório de identidades
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{
+		db: db,
+	}
+}
+
+// FindByProviderSubject busca uma identidade pelo provedor e pelo subject emitido por ele
+func (r *IdentityRepository) FindByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	result := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &identity, nil
+}
+
+// ListByUser lista as identidades vinculadas a um usuário
+func (r *IdentityRepository) ListByUser(userID string) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Create cria uma nova identidade vinculada
+func (r *IdentityRepository) Create(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// Update atualiza uma identidade existente
+func (r *IdentityRepository) Update(identity *models.UserIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+// Delete remove uma identidade vinculada
+func (r *IdentityRepository) Delete(identity *models.UserIdentity) error {
+	return r.db.Delete(identity).Error
+}
+
+// MigrateLegacyGoogleIDs copia a antiga coluna users.google_id para a tabela de
+// identidades vinculadas e remove a coluna
+func (r *IdentityRepository) MigrateLegacyGoogleIDs() error {
+	if !r.db.Migrator().HasColumn(&models.User{}, "google_id") {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO user_identities (id, user_id, provider, subject, email, email_verified, created_at, updated_at)
+			SELECT gen_random_uuid(), id, 'google', google_id, email, true, created_at, NOW()
+			FROM users
+			WHERE google_id IS NOT NULL AND google_id <> ''
+			ON CONFLICT (provider, subject) DO NOTHING`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&models.User{}, "google_id")
+	})
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvitationRepository manipula operações de banco de dados relacionadas aos convites
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository cria um novo repositório de convites
+func NewInvitationRepository(db *gorm.DB) *InvitationRepository {
+	return &InvitationRepository{
+		db: db,
+	}
+}
+
+// Create salva um novo convite com seus papéis e grupos
+func (r *InvitationRepository) Create(invitation *models.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// FindByID busca um convite da organização pelo ID, retornando nil se não existir
+func (r *InvitationRepository) FindByID(orgID, id uuid.UUID) (*models.Invitation, error) {
+	return r.first(r.db.Where("organization_id = ? AND id = ?", orgID, id))
+}
+
+// FindByTokenHash busca um convite pelo hash do token do link, retornando nil se não existir
+func (r *InvitationRepository) FindByTokenHash(tokenHash string) (*models.Invitation, error) {
+	return r.first(r.db.Where("token_hash = ?", tokenHash))
+}
+
+// FindForLogin busca o convite vinculado a um login em andamento, de qualquer
+// organização, retornando nil se não existir
+func (r *InvitationRepository) FindForLogin(id uuid.UUID) (*models.Invitation, error) {
+	return r.first(r.db.Where("id = ?", id))
+}
+
+// FindPendingByEmail busca um convite pendente da organização para o e-mail,
+// retornando nil se não houver
+func (r *InvitationRepository) FindPendingByEmail(orgID uuid.UUID, email string) (*models.Invitation, error) {
+	return r.first(r.db.Where("organization_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?",
+		orgID, email, time.Now()))
+}
+
+// List lista os convites da organização, do mais recente para o mais antigo
+func (r *InvitationRepository) List(orgID uuid.UUID) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	err := r.db.Where("organization_id = ?", orgID).Preload("Roles").Preload("Groups").
+		Order("created_at DESC").Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Reissue substitui o token e a validade de um convite ainda não aceito nem
+// revogado, invalidando o link anterior. Retorna false se o convite não puder
+// mais ser reenviado.
+func (r *InvitationRepository) Reissue(invitation *models.Invitation, tokenHash string, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+		Updates(map[string]interface{}{"token_hash": tokenHash, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = expiresAt
+	return true, nil
+}
+
+// MarkSent registra o envio do e-mail do convite
+func (r *InvitationRepository) MarkSent(invitation *models.Invitation) error {
+	now := time.Now()
+	if err := r.db.Model(invitation).Update("sent_at", now).Error; err != nil {
+		return err
+	}
+	invitation.SentAt = &now
+	return nil
+}
+
+// Revoke revoga um convite ainda não aceito, retornando false se ele já tiver
+// sido aceito ou revogado
+func (r *InvitationRepository) Revoke(invitation *models.Invitation) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	invitation.RevokedAt = &now
+	return true, nil
+}
+
+// Accept marca o convite pendente como aceito pelo usuário e o torna membro da
+// organização com os papéis e grupos do convite. Quem já é membro recebe os
+// papéis e grupos além dos que já tinha. Retorna false se o convite não estiver
+// mais pendente.
+func (r *InvitationRepository) Accept(invitation *models.Invitation, userID uuid.UUID) (bool, error) {
+	accepted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", invitation.ID, now).
+			Updates(map[string]interface{}{"accepted_at": now, "accepted_by_id": userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var membership models.Membership
+		err := tx.Where("organization_id = ? AND user_id = ?", invitation.OrganizationID, userID).First(&membership).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			membership = models.Membership{
+				OrganizationID: invitation.OrganizationID,
+				UserID:         userID,
+				Roles:          invitation.Roles,
+			}
+			if err := tx.Create(&membership).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case len(invitation.Roles) > 0:
+			if err := tx.Model(&membership).Association("Roles").Append(invitation.Roles); err != nil {
+				return err
+			}
+		}
+
+		for _, group := range invitation.Groups {
+			if err := tx.Exec("INSERT INTO user_groups (user_id, group_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userID, group.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		invitation.AcceptedAt = &now
+		invitation.AcceptedByID = &userID
+		accepted = true
+		return bumpAuthzVersion(tx, "id = ?", userID)
+	})
+	return accepted, err
+}
+
+// first busca o primeiro convite da consulta com seus papéis e grupos,
+// retornando nil se não existir
+func (r *InvitationRepository) first(query *gorm.DB) (*models.Invitation, error) {
+	var invitation models.Invitation
+	result := query.Preload("Roles").Preload("Groups").First(&invitation)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &invitation, nil
+}
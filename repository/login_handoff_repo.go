@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginHandoffRepository manipula operações de banco de dados relacionadas aos códigos de troca de login
+type LoginHandoffRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginHandoffRepository cria um novo repositório de códigos de troca de login
+func NewLoginHandoffRepository(db *gorm.DB) *LoginHandoffRepository {
+	return &LoginHandoffRepository{
+		db: db,
+	}
+}
+
+// Create salva um novo handoff de login
+func (r *LoginHandoffRepository) Create(handoff *models.LoginHandoff) error {
+	return r.db.Create(handoff).Error
+}
+
+// FindByHash busca um handoff pelo hash do código emitido
+func (r *LoginHandoffRepository) FindByHash(codeHash string) (*models.LoginHandoff, error) {
+	var handoff models.LoginHandoff
+	result := r.db.Where("code_hash = ?", codeHash).First(&handoff)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &handoff, nil
+}
+
+// MarkConsumed marca o código como utilizado e apaga o payload, retornando
+// false se ele já tiver sido consumido
+func (r *LoginHandoffRepository) MarkConsumed(handoff *models.LoginHandoff) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.LoginHandoff{}).
+		Where("id = ? AND consumed_at IS NULL", handoff.ID).
+		Updates(map[string]interface{}{"consumed_at": now, "payload": ""})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	handoff.ConsumedAt = &now
+	return true, nil
+}
+
+// DeleteExpired remove os handoffs expirados antes do instante informado
+func (r *LoginHandoffRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.LoginHandoff{}).Error
+}
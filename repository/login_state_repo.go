@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginStateRepository manipula operações de banco de dados relacionadas aos states de login OAuth
+type LoginStateRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginStateRepository cria um novo repositório de states de login
+func NewLoginStateRepository(db *gorm.DB) *LoginStateRepository {
+	return &LoginStateRepository{
+		db: db,
+	}
+}
+
+// Create salva um novo state de login
+func (r *LoginStateRepository) Create(state *models.LoginState) error {
+	return r.db.Create(state).Error
+}
+
+// FindByHash busca um state de login pelo hash do valor emitido
+func (r *LoginStateRepository) FindByHash(stateHash string) (*models.LoginState, error) {
+	var state models.LoginState
+	result := r.db.Where("state_hash = ?", stateHash).First(&state)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &state, nil
+}
+
+// MarkConsumed marca o state como utilizado, retornando false se ele já tiver sido consumido
+func (r *LoginStateRepository) MarkConsumed(state *models.LoginState) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.LoginState{}).
+		Where("id = ? AND consumed_at IS NULL", state.ID).
+		Update("consumed_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	state.ConsumedAt = &now
+	return true, nil
+}
+
+// DeleteExpired remove os states expirados antes do instante informado
+func (r *LoginStateRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.LoginState{}).Error
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// legacyOrganizationName é o nome da organização que recebe os dados criados
+// antes da separação por organizações
+const legacyOrganizationName = "Organização padrão"
+
+// OrganizationRepository manipula operações de banco de dados relacionadas às
+// organizações e às associações dos usuários
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository cria um novo repositório de organizações
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{
+		db: db,
+	}
+}
+
+// FindByID busca uma organização pelo ID, retornando nil se não existir
+func (r *OrganizationRepository) FindByID(id uuid.UUID) (*models.Organization, error) {
+	var organization models.Organization
+	result := r.db.Where("id = ?", id).First(&organization)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &organization, nil
+}
+
+// Create cria a organização com os papéis padrão e torna o usuário informado
+// administrador dela
+func (r *OrganizationRepository) Create(organization *models.Organization, ownerID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+
+		var admin models.Role
+		for _, role := range models.GetDefaultRoles() {
+			role.OrganizationID = organization.ID
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+			if role.Name == models.RoleAdmin {
+				admin = role
+			}
+		}
+
+		membership := &models.Membership{
+			OrganizationID: organization.ID,
+			UserID:         ownerID,
+			Roles:          []models.Role{admin},
+		}
+		if err := tx.Create(membership).Error; err != nil {
+			return err
+		}
+		return bumpAuthzVersion(tx, "id = ?", ownerID)
+	})
+}
+
+// Update atualiza os dados de uma organização
+func (r *OrganizationRepository) Update(organization *models.Organization) error {
+	return r.db.Save(organization).Error
+}
+
+// FindMembership busca a associação do usuário à organização com seus papéis,
+// retornando nil se o usuário não participar dela
+func (r *OrganizationRepository) FindMembership(orgID, userID uuid.UUID) (*models.Membership, error) {
+	var membership models.Membership
+	result := r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).Preload("Roles").First(&membership)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &membership, nil
+}
+
+// ListMemberships lista as associações do usuário, com as organizações e os
+// papéis, da mais antiga para a mais recente
+func (r *OrganizationRepository) ListMemberships(userID uuid.UUID) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := r.db.Where("user_id = ?", userID).Preload("Organization").Preload("Roles").
+		Order("created_at").Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// CreateMembership adiciona o usuário à organização com os papéis da associação
+func (r *OrganizationRepository) CreateMembership(membership *models.Membership) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(membership).Error; err != nil {
+			return err
+		}
+		return bumpAuthzVersion(tx, "id = ?", membership.UserID)
+	})
+}
+
+// SetMembershipRoles substitui os papéis do usuário na organização
+func (r *OrganizationRepository) SetMembershipRoles(membership *models.Membership, roles []models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(membership).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+		return bumpAuthzVersion(tx, "id = ?", membership.UserID)
+	})
+}
+
+// DeleteMembership remove o usuário da organização, junto com seus grupos e
+// atribuições de papel com escopo na organização
+func (r *OrganizationRepository) DeleteMembership(membership *models.Membership) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM user_groups WHERE user_id = ?
+			AND group_id IN (SELECT id FROM groups WHERE organization_id = ?)`, membership.UserID, membership.OrganizationID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("organization_id = ? AND subject_type = ? AND subject_id = ?", membership.OrganizationID, models.SubjectUser, membership.UserID).
+			Delete(&models.RoleBinding{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(membership).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(membership).Error; err != nil {
+			return err
+		}
+		return bumpAuthzVersion(tx, "id = ?", membership.UserID)
+	})
+}
+
+// CountAdmins conta os membros da organização com o papel admin atribuído diretamente
+func (r *OrganizationRepository) CountAdmins(orgID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Membership{}).
+		Joins("JOIN membership_roles ON membership_roles.membership_id = memberships.id").
+		Joins("JOIN roles ON roles.id = membership_roles.role_id").
+		Where("memberships.organization_id = ? AND roles.name = ? AND roles.system", orgID, models.RoleAdmin).
+		Distinct("memberships.id").Count(&count).Error
+	return count, err
+}
+
+// MigrateLegacyData move os dados criados antes da separação por organizações
+// para uma organização padrão: grupos, papéis, atribuições com escopo e
+// políticas sem organização passam a pertencer a ela, e todos os usuários se
+// tornam membros com os papéis de user_roles e os atributos de users.attributes
+func (r *OrganizationRepository) MigrateLegacyData() error {
+	var organizations, users int64
+	if err := r.db.Model(&models.Organization{}).Count(&organizations).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(&models.User{}).Count(&users).Error; err != nil {
+		return err
+	}
+	if organizations > 0 || users == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		organization := &models.Organization{Name: legacyOrganizationName}
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.Group{}, &models.Role{}, &models.RoleBinding{}, &models.Policy{}} {
+			if err := tx.Model(model).Where("organization_id IS NULL").Update("organization_id", organization.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.Exec(`INSERT INTO memberships (id, organization_id, user_id, created_at)
+			SELECT gen_random_uuid(), ?, id, created_at FROM users`, organization.ID).Error
+		if err != nil {
+			return err
+		}
+
+		if tx.Migrator().HasTable("user_roles") {
+			err := tx.Exec(`INSERT INTO membership_roles (membership_id, role_id)
+				SELECT memberships.id, user_roles.role_id FROM user_roles
+				JOIN memberships ON memberships.user_id = user_roles.user_id
+				ON CONFLICT DO NOTHING`).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if tx.Migrator().HasColumn(&models.User{}, "attributes") {
+			err := tx.Exec(`UPDATE memberships SET attributes = users.attributes
+				FROM users WHERE users.id = memberships.user_id`).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Os papéis e grupos dos usuários mudaram de origem
+		return bumpAuthzVersion(tx, "1 = 1")
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PermissionRepository manipula operações de banco de dados relacionadas ao catálogo de permissões
+type PermissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository cria um novo repositório do catálogo de permissões
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{
+		db: db,
+	}
+}
+
+// FindByName busca uma permissão pelo nome, retornando nil se não existir
+func (r *PermissionRepository) FindByName(name string) (*models.Permission, error) {
+	var permission models.Permission
+	result := r.db.Where("name = ?", name).First(&permission)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &permission, nil
+}
+
+// ListAll lista o catálogo de permissões agrupado por módulo
+func (r *PermissionRepository) ListAll() ([]models.Permission, error) {
+	var permissions []models.Permission
+	if err := r.db.Order("module").Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// Create registra uma nova permissão no catálogo
+func (r *PermissionRepository) Create(permission *models.Permission) error {
+	return r.db.Create(permission).Error
+}
+
+// EnsureAll registra as permissões que ainda não estão no catálogo
+func (r *PermissionRepository) EnsureAll(permissions []models.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&permissions).Error
+}
+
+// FindMissing retorna os nomes informados que não estão registrados no catálogo
+func (r *PermissionRepository) FindMissing(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var registered []string
+	if err := r.db.Model(&models.Permission{}).Where("name IN ?", names).Pluck("name", &registered).Error; err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(registered))
+	for _, name := range registered {
+		known[name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !known[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PolicyRepository manipula operações de banco de dados relacionadas às políticas de acesso
+type PolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewPolicyRepository cria um novo repositório de políticas
+func NewPolicyRepository(db *gorm.DB) *PolicyRepository {
+	return &PolicyRepository{
+		db: db,
+	}
+}
+
+// FindByID busca uma política da organização pelo ID, retornando nil se não existir
+func (r *PolicyRepository) FindByID(orgID, id uuid.UUID) (*models.Policy, error) {
+	var policy models.Policy
+	result := r.db.Where("organization_id = ? AND id = ?", orgID, id).First(&policy)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &policy, nil
+}
+
+// FindByName busca uma política da organização pelo nome, retornando nil se não existir
+func (r *PolicyRepository) FindByName(orgID uuid.UUID, name string) (*models.Policy, error) {
+	var policy models.Policy
+	result := r.db.Where("organization_id = ? AND name = ?", orgID, name).First(&policy)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &policy, nil
+}
+
+// ListAll lista todas as políticas da organização, ordenadas pelo nome
+func (r *PolicyRepository) ListAll(orgID uuid.UUID) ([]models.Policy, error) {
+	var policies []models.Policy
+	if err := r.db.Where("organization_id = ?", orgID).Order("name").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Create cria uma nova política
+func (r *PolicyRepository) Create(policy *models.Policy) error {
+	return r.db.Create(policy).Error
+}
+
+// Update atualiza uma política existente
+func (r *PolicyRepository) Update(policy *models.Policy) error {
+	return r.db.Save(policy).Error
+}
+
+// Delete exclui uma política
+func (r *PolicyRepository) Delete(policy *models.Policy) error {
+	return r.db.Delete(policy).Error
+}
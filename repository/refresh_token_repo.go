@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository manipula operações de banco de dados relacionadas aos tokens de atualização
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository cria um novo repositório de tokens de atualização
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Create salva um novo token de atualização
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindByHash busca um token de atualização pelo hash
+func (r *RefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.db.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// Rotate marca o token como rotacionado e grava o token que o substitui em uma
+// única transação, retornando false, sem gravar o substituto, se outro processo
+// já tiver utilizado o token
+func (r *RefreshTokenRepository) Rotate(tokenID uuid.UUID, successor *models.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", tokenID).
+			Updates(map[string]interface{}{"rotated_at": time.Now(), "replaced_by_id": successor.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(successor).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return rotated, nil
+}
+
+// RevokeFamily revoga todos os tokens ainda válidos de uma família
+func (r *RefreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revoga todos os tokens ainda válidos do usuário
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired remove os tokens expirados antes do instante informado
+func (r *RefreshTokenRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{}).Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"go-google/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RelationTupleRepository manipula operações de banco de dados relacionadas às tuplas de relacionamento
+type RelationTupleRepository struct {
+	db *gorm.DB
+}
+
+// NewRelationTupleRepository cria um novo repositório de tuplas de relacionamento
+func NewRelationTupleRepository(db *gorm.DB) *RelationTupleRepository {
+	return &RelationTupleRepository{
+		db: db,
+	}
+}
+
+// Find busca as tuplas de um objeto com a relação na organização
+func (r *RelationTupleRepository) Find(orgID uuid.UUID, namespace, objectID, relation string) ([]models.RelationTuple, error) {
+	var tuples []models.RelationTuple
+	err := r.db.Where("organization_id = ? AND namespace = ? AND object_id = ? AND relation = ?", orgID, namespace, objectID, relation).
+		Order("created_at").Find(&tuples).Error
+	if err != nil {
+		return nil, err
+	}
+	return tuples, nil
+}
+
+// List lista as tuplas da organização que atendem ao filtro
+func (r *RelationTupleRepository) List(orgID uuid.UUID, filter models.RelationTupleFilter) ([]models.RelationTuple, error) {
+	query := r.db.Where("organization_id = ?", orgID).Order("namespace").Order("object_id").Order("relation").Order("created_at")
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
+	}
+	if filter.ObjectID != "" {
+		query = query.Where("object_id = ?", filter.ObjectID)
+	}
+	if filter.Relation != "" {
+		query = query.Where("relation = ?", filter.Relation)
+	}
+	if filter.Subject != nil {
+		query = query.Where("subject_namespace = ? AND subject_id = ? AND subject_relation = ?",
+			filter.Subject.Object.Namespace, filter.Subject.Object.ID, filter.Subject.Relation)
+	}
+
+	var tuples []models.RelationTuple
+	if err := query.Find(&tuples).Error; err != nil {
+		return nil, err
+	}
+	return tuples, nil
+}
+
+// ListObjectIDs lista os IDs dos objetos do namespace que têm tuplas na organização
+func (r *RelationTupleRepository) ListObjectIDs(orgID uuid.UUID, namespace string) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&models.RelationTuple{}).Where("organization_id = ? AND namespace = ?", orgID, namespace).
+		Distinct().Pluck("object_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Create grava uma tupla; gravar uma tupla já existente não tem efeito
+func (r *RelationTupleRepository) Create(tuple *models.RelationTuple) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(tuple).Error
+}
+
+// Delete remove uma tupla da organização, indicando se ela existia
+func (r *RelationTupleRepository) Delete(tuple *models.RelationTuple) (bool, error) {
+	result := r.db.Where(
+		"organization_id = ? AND namespace = ? AND object_id = ? AND relation = ? AND subject_namespace = ? AND subject_id = ? AND subject_relation = ?",
+		tuple.OrganizationID, tuple.Namespace, tuple.ObjectID, tuple.Relation, tuple.SubjectNamespace, tuple.SubjectID, tuple.SubjectRelation,
+	).Delete(&models.RelationTuple{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MigrateOrganizationScope move as tuplas gravadas antes da separação por
+// organizações para a organização padrão e remove o índice único global, que
+// impediria a mesma tupla em organizações diferentes
+func (r *RelationTupleRepository) MigrateOrganizationScope() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if tx.Migrator().HasIndex(&models.RelationTuple{}, "idx_relation_tuple") {
+			if err := tx.Migrator().DropIndex(&models.RelationTuple{}, "idx_relation_tuple"); err != nil {
+				return err
+			}
+		}
+		if tx.Migrator().HasIndex(&models.RelationTuple{}, "idx_relation_tuple_object") {
+			if err := tx.Migrator().DropIndex(&models.RelationTuple{}, "idx_relation_tuple_object"); err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`UPDATE relation_tuples SET organization_id = (
+			SELECT id FROM organizations WHERE name = ? ORDER BY created_at LIMIT 1
+		) WHERE organization_id IS NULL`, legacyOrganizationName).Error
+	})
+}
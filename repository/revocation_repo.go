@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"go-google/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevocationRepository manipula operações de banco de dados relacionadas às revogações de tokens
+type RevocationRepository struct {
+	db *gorm.DB
+}
+
+// NewRevocationRepository cria um novo repositório de revogações
+func NewRevocationRepository(db *gorm.DB) *RevocationRepository {
+	return &RevocationRepository{
+		db: db,
+	}
+}
+
+// Save registra uma revogação, substituindo uma anterior do mesmo tipo e ID
+func (r *RevocationRepository) Save(revocation *models.RevokedToken) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(revocation).Error
+}
+
+// ListActive lista as revogações que ainda afetam tokens válidos
+func (r *RevocationRepository) ListActive(now time.Time) ([]models.RevokedToken, error) {
+	var revocations []models.RevokedToken
+	if err := r.db.Where("expires_at > ?", now).Find(&revocations).Error; err != nil {
+		return nil, err
+	}
+	return revocations, nil
+}
+
+// DeleteExpired remove as revogações que não afetam mais nenhum token
+func (r *RevocationRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at <= ?", before).Delete(&models.RevokedToken{}).Error
+}
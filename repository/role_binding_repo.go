@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleBindingRepository manipula operações de banco de dados relacionadas às atribuições de papel com escopo
+type RoleBindingRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleBindingRepository cria um novo repositório de atribuições de papel com escopo
+func NewRoleBindingRepository(db *gorm.DB) *RoleBindingRepository {
+	return &RoleBindingRepository{
+		db: db,
+	}
+}
+
+// FindByID busca uma atribuição da organização pelo ID, retornando nil se não existir
+func (r *RoleBindingRepository) FindByID(orgID, id uuid.UUID) (*models.RoleBinding, error) {
+	var binding models.RoleBinding
+	result := r.db.Where("organization_id = ? AND id = ?", orgID, id).Preload("Role").First(&binding)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &binding, nil
+}
+
+// List lista as atribuições da organização que atendem ao filtro
+func (r *RoleBindingRepository) List(orgID uuid.UUID, filter models.RoleBindingFilter) ([]models.RoleBinding, error) {
+	query := r.db.Where("organization_id = ?", orgID).Preload("Role").Order("resource_type").Order("resource_id").Order("created_at")
+	if filter.SubjectType != "" {
+		query = query.Where("subject_type = ?", filter.SubjectType)
+	}
+	if filter.SubjectID != "" {
+		query = query.Where("subject_id = ?", filter.SubjectID)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+
+	var bindings []models.RoleBinding
+	if err := query.Find(&bindings).Error; err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+// FindRoleIDs retorna os papéis atribuídos na organização ao usuário ou a um
+// dos grupos informados sobre o recurso
+func (r *RoleBindingRepository) FindRoleIDs(orgID, userID uuid.UUID, groupIDs []uuid.UUID, resourceType, resourceID string) ([]uuid.UUID, error) {
+	query := r.db.Model(&models.RoleBinding{}).
+		Where("organization_id = ? AND resource_type = ? AND resource_id = ?", orgID, resourceType, resourceID)
+	if len(groupIDs) > 0 {
+		query = query.Where("(subject_type = ? AND subject_id = ?) OR (subject_type = ? AND subject_id IN ?)",
+			models.SubjectUser, userID, models.SubjectGroup, groupIDs)
+	} else {
+		query = query.Where("subject_type = ? AND subject_id = ?", models.SubjectUser, userID)
+	}
+
+	var roleIDs []uuid.UUID
+	if err := query.Distinct().Pluck("role_id", &roleIDs).Error; err != nil {
+		return nil, err
+	}
+	return roleIDs, nil
+}
+
+// Create cria uma nova atribuição
+func (r *RoleBindingRepository) Create(binding *models.RoleBinding) error {
+	return r.db.Create(binding).Error
+}
+
+// Delete exclui uma atribuição
+func (r *RoleBindingRepository) Delete(binding *models.RoleBinding) error {
+	return r.db.Delete(binding).Error
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleRepository manipula operações de banco de dados relacionadas a papéis
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository cria um novo repositório de papéis
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+// FindByID busca um papel da organização pelo ID, retornando nil se não existir
+func (r *RoleRepository) FindByID(orgID, id uuid.UUID) (*models.Role, error) {
+	var role models.Role
+	result := r.db.Where("organization_id = ? AND id = ?", orgID, id).Preload("Parents").First(&role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &role, nil
+}
+
+// FindByName busca um papel da organização pelo nome, retornando nil se não existir
+func (r *RoleRepository) FindByName(orgID uuid.UUID, name string) (*models.Role, error) {
+	var role models.Role
+	result := r.db.Where("organization_id = ? AND name = ?", orgID, name).Preload("Parents").First(&role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &role, nil
+}
+
+// ListAll lista todos os papéis da organização
+func (r *RoleRepository) ListAll(orgID uuid.UUID) ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.db.Where("organization_id = ?", orgID).Preload("Parents").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Create cria um novo papel
+func (r *RoleRepository) Create(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+// Update atualiza um papel e seus papéis pais e invalida as permissões em
+// cache de quem o possui, diretamente ou por herança
+func (r *RoleRepository) Update(role *models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Groups", "Parents").Save(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(role).Association("Parents").Replace(role.Parents); err != nil {
+			return err
+		}
+		return bumpRoleHolders(tx, role.ID)
+	})
+}
+
+// FindWithAncestors busca os papéis informados da organização e todos os
+// papéis dos quais eles herdam, direta ou indiretamente
+func (r *RoleRepository) FindWithAncestors(orgID uuid.UUID, ids []uuid.UUID) ([]models.Role, error) {
+	var roles []models.Role
+	if len(ids) == 0 {
+		return roles, nil
+	}
+
+	err := r.db.Raw(`WITH RECURSIVE lineage(id) AS (
+			SELECT id FROM roles WHERE id IN ? AND organization_id = ?
+			UNION
+			SELECT role_parents.parent_id FROM role_parents JOIN lineage ON role_parents.role_id = lineage.id
+		)
+		SELECT * FROM roles WHERE id IN (SELECT id FROM lineage) ORDER BY name`, ids, orgID).Scan(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Delete exclui um papel, removendo-o dos membros, grupos e convites
+func (r *RoleRepository) Delete(role *models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// A versão é incrementada antes de remover as associações que a localizam
+		if err := bumpRoleHolders(tx, role.ID); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM membership_roles WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM invitation_roles WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(role).Association("Groups").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(role).Association("Parents").Clear(); err != nil {
+			return err
+		}
+		// Os papéis que herdavam deste deixam de herdar
+		if err := tx.Exec("DELETE FROM role_parents WHERE parent_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RoleBinding{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+}
+
+// MarkSystemRoles marca como papéis do sistema os papéis com os nomes informados
+func (r *RoleRepository) MarkSystemRoles(names []string) error {
+	return r.db.Model(&models.Role{}).Where("name IN ?", names).Update("system", true).Error
+}
+
+// bumpRoleHolders incrementa a versão de autorização de todos os usuários que
+// possuem o papel ou um papel que herda dele, como membros da organização ou por um grupo
+// (inclusive um grupo ancestral)
+func bumpRoleHolders(tx *gorm.DB, roleID uuid.UUID) error {
+	var lineage []uuid.UUID
+	err := tx.Raw(`WITH RECURSIVE lineage(id) AS (
+			SELECT CAST(? AS uuid)
+			UNION
+			SELECT role_parents.role_id FROM role_parents JOIN lineage ON role_parents.parent_id = lineage.id
+		)
+		SELECT id FROM lineage`, roleID).Scan(&lineage).Error
+	if err != nil {
+		return err
+	}
+
+	members := tx.Table("memberships").Select("memberships.user_id").
+		Joins("JOIN membership_roles ON membership_roles.membership_id = memberships.id").
+		Where("membership_roles.role_id IN ?", lineage)
+	if err := bumpAuthzVersion(tx, "id IN (?)", members); err != nil {
+		return err
+	}
+
+	// Membros dos grupos com o papel e dos seus subgrupos
+	var groupIDs []uuid.UUID
+	if err := tx.Table("group_roles").Where("role_id IN ?", lineage).Pluck("group_id", &groupIDs).Error; err != nil {
+		return err
+	}
+	return bumpGroupMembers(tx, groupIDs)
+}
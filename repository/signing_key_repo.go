@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"go-google/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepository manipula operações de banco de dados relacionadas às chaves de assinatura
+type SigningKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewSigningKeyRepository cria um novo repositório de chaves de assinatura
+func NewSigningKeyRepository(db *gorm.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		db: db,
+	}
+}
+
+// Create armazena uma nova chave de assinatura
+func (r *SigningKeyRepository) Create(key *models.SigningKey) error {
+	return r.db.Create(key).Error
+}
+
+// FindByID busca uma chave pelo kid, retornando nil se não existir
+func (r *SigningKeyRepository) FindByID(kid string) (*models.SigningKey, error) {
+	var key models.SigningKey
+	if err := r.db.Where("id = ?", kid).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListAll lista todas as chaves, da mais recente para a mais antiga
+func (r *SigningKeyRepository) ListAll() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	if err := r.db.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ListActive lista as chaves ativas, da mais recente para a mais antiga
+func (r *SigningKeyRepository) ListActive() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	if err := r.db.Where("status = ?", models.SigningKeyActive).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ListUsable lista as chaves que ainda verificam tokens: as ativas e as
+// substituídas ainda dentro do período de carência
+func (r *SigningKeyRepository) ListUsable(now time.Time) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.Where("status = ? OR (status = ? AND verify_until > ?)", models.SigningKeyActive, models.SigningKeyVerifyOnly, now).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Demote deixa de assinar com a chave, mantendo-a apenas para verificação até
+// verifyUntil. Retorna false se a chave já não estava ativa (outra instância a rotacionou).
+func (r *SigningKeyRepository) Demote(kid string, verifyUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.SigningKey{}).
+		Where("id = ? AND status = ?", kid, models.SigningKeyActive).
+		Updates(map[string]interface{}{
+			"status":       models.SigningKeyVerifyOnly,
+			"rotated_at":   time.Now(),
+			"verify_until": verifyUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Retire aposenta a chave imediatamente: tokens assinados com ela deixam de ser aceitos
+func (r *SigningKeyRepository) Retire(kid string) error {
+	return r.db.Model(&models.SigningKey{}).
+		Where("id = ? AND status <> ?", kid, models.SigningKeyRetired).
+		Updates(map[string]interface{}{
+			"status":     models.SigningKeyRetired,
+			"retired_at": time.Now(),
+		}).Error
+}
+
+// RetireExpired aposenta as chaves cujo período de carência terminou
+func (r *SigningKeyRepository) RetireExpired(now time.Time) error {
+	return r.db.Model(&models.SigningKey{}).
+		Where("status = ? AND verify_until <= ?", models.SigningKeyVerifyOnly, now).
+		Updates(map[string]interface{}{
+			"status":     models.SigningKeyRetired,
+			"retired_at": now,
+		}).Error
+}
@@ -1,11 +1,13 @@
 package repository
 
 import (
-	"go-google/models"
 	"errors"
+	"go-google/models"
+	"go-google/policy"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository manipula operações de banco de dados relacionadas a usuários
@@ -20,10 +22,10 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	}
 }
 
-// FindByGoogleID busca um usuário pelo ID do Google
-func (r *UserRepository) FindByGoogleID(googleID string) (*models.User, error) {
+// FindByEmail busca um usuário pelo e-mail
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("google_id = ?", googleID).Preload("Groups").Preload("Roles").First(&user)
+	result := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -33,13 +35,45 @@ func (r *UserRepository) FindByGoogleID(googleID string) (*models.User, error) {
 	return &user, nil
 }
 
-// FindByID busca um usuário pelo ID
+// FindByID busca um usuário pelo ID, sem os grupos, papéis e atributos, que
+// dependem da organização
 func (r *UserRepository) FindByID(id string) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("id = ?", id).Preload("Groups").Preload("Groups.Roles").Preload("Roles").First(&user)
+	result := r.db.Where("id = ?", id).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// FindMember busca um usuário como membro da organização, retornando
+// gorm.ErrRecordNotFound se ele não existir ou não participar dela. Roles e
+// Attributes vêm da associação, e Groups traz os grupos diretos da organização
+// e os grupos que os contêm (transitivamente), com seus papéis.
+func (r *UserRepository) FindMember(orgID uuid.UUID, id string) (*models.User, error) {
+	var membership models.Membership
+	result := r.db.Where("organization_id = ? AND user_id = ?", orgID, id).Preload("User").Preload("Roles").First(&membership)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+
+	user := membership.User
+	user.Roles = membership.Roles
+	user.Attributes = membership.Attributes
+
+	// Carregar os grupos do usuário e todos os grupos ancestrais, cujos papéis
+	// são herdados, em uma única consulta recursiva
+	lineage := r.db.Raw(`WITH RECURSIVE lineage(id) AS (
+			SELECT user_groups.group_id FROM user_groups
+			JOIN groups ON groups.id = user_groups.group_id
+			WHERE user_groups.user_id = ? AND groups.organization_id = ?
+			UNION
+			SELECT group_parents.parent_id FROM group_parents JOIN lineage ON group_parents.group_id = lineage.id
+		)
+		SELECT id FROM lineage`, user.ID, orgID)
+	if err := r.db.Where("id IN (?)", lineage).Preload("Roles").Order("name").Find(&user.Groups).Error; err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
@@ -48,38 +82,91 @@ func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
-// Update atualiza um usuário existente
+// Update atualiza os dados de um usuário existente. As associações carregadas
+// (grupos, incluindo os herdados, e papéis da organização) não são gravadas.
 func (r *UserRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit(clause.Associations).Save(user).Error
 }
 
-// ListAll lista todos os usuários
-func (r *UserRepository) ListAll() ([]models.User, error) {
-	var users []models.User
-	if err := r.db.Preload("Groups").Preload("Roles").Find(&users).Error; err != nil {
+// ListMembers lista os membros da organização com seus papéis, atributos e
+// grupos diretos na organização
+func (r *UserRepository) ListMembers(orgID uuid.UUID) ([]models.User, error) {
+	var memberships []models.Membership
+	err := r.db.Where("organization_id = ?", orgID).
+		Preload("User").Preload("User.Groups", "organization_id = ?", orgID).Preload("Roles").
+		Order("created_at").Find(&memberships).Error
+	if err != nil {
 		return nil, err
 	}
+
+	users := make([]models.User, 0, len(memberships))
+	for _, membership := range memberships {
+		user := membership.User
+		user.Roles = membership.Roles
+		user.Attributes = membership.Attributes
+		users = append(users, user)
+	}
 	return users, nil
 }
 
-// AssignToGroups atribui um usuário a grupos
-func (r *UserRepository) AssignToGroups(userID string, groupIDs []string) error {
-	// Converter string IDs para UUIDs
-	var groups []models.Group
-	for _, id := range groupIDs {
-		groupID, err := uuid.Parse(id)
+// AssignToGroups substitui os grupos da organização aos quais o usuário
+// pertence, preservando os grupos das outras organizações
+func (r *UserRepository) AssignToGroups(orgID, userID uuid.UUID, groupIDs []uuid.UUID) error {
+	// Associar usuário a grupos e invalidar as permissões em cache
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM user_groups WHERE user_id = ?
+			AND group_id IN (SELECT id FROM groups WHERE organization_id = ?)`, userID, orgID).Error
 		if err != nil {
 			return err
 		}
-		groups = append(groups, models.Group{ID: groupID})
-	}
+		for _, groupID := range groupIDs {
+			if err := tx.Exec("INSERT INTO user_groups (user_id, group_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userID, groupID).Error; err != nil {
+				return err
+			}
+		}
+		return bumpAuthzVersion(tx, "id = ?", userID)
+	})
+}
 
-	// Buscar usuário
-	user, err := r.FindByID(userID)
+// FindGroupIDs retorna os IDs dos grupos da organização aos quais o usuário
+// pertence diretamente, sem os grupos ancestrais
+func (r *UserRepository) FindGroupIDs(orgID, id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Table("user_groups").Joins("JOIN groups ON groups.id = user_groups.group_id").
+		Where("user_groups.user_id = ? AND groups.organization_id = ?", id, orgID).Pluck("user_groups.group_id", &ids).Error
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return ids, nil
+}
 
-	// Associar usuário a grupos
-	return r.db.Model(user).Association("Groups").Replace(groups)
-}
\ No newline at end of file
+// UpdateAttributes substitui os atributos do usuário na organização, usados nas
+// políticas de acesso, e invalida as permissões em cache
+func (r *UserRepository) UpdateAttributes(orgID, id uuid.UUID, attributes policy.Attributes) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Membership{}).Where("organization_id = ? AND user_id = ?", orgID, id).
+			Update("attributes", attributes).Error
+		if err != nil {
+			return err
+		}
+		return bumpAuthzVersion(tx, "id = ?", id)
+	})
+}
+
+// FindAuthzVersion retorna a versão de autorização do usuário. found é falso
+// quando o usuário não existe mais.
+func (r *UserRepository) FindAuthzVersion(id string) (version int64, found bool, err error) {
+	var user models.User
+	result := r.db.Select("authz_version").Where("id = ?", id).Limit(1).Find(&user)
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	return user.AuthzVersion, result.RowsAffected > 0, nil
+}
+
+// bumpAuthzVersion incrementa a versão de autorização dos usuários que atendem
+// à condição, sinalizando que suas permissões efetivas mudaram
+func bumpAuthzVersion(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	return tx.Model(&models.User{}).Where(query, args...).
+		UpdateColumn("authz_version", gorm.Expr("authz_version + 1")).Error
+}
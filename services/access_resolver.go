@@ -0,0 +1,128 @@
+package services
+
+import (
+	"go-google/models"
+	"go-google/permission"
+	"go-google/repository"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// AccessResolver calcula os papéis e permissões efetivos dos usuários,
+// seguindo a herança de papéis
+type AccessResolver struct {
+	roleRepo    *repository.RoleRepository
+	groupRepo   *repository.GroupRepository
+	bindingRepo *repository.RoleBindingRepository
+}
+
+// NewAccessResolver cria um novo resolvedor de acesso
+func NewAccessResolver(roleRepo *repository.RoleRepository, groupRepo *repository.GroupRepository, bindingRepo *repository.RoleBindingRepository) *AccessResolver {
+	return &AccessResolver{
+		roleRepo:    roleRepo,
+		groupRepo:   groupRepo,
+		bindingRepo: bindingRepo,
+	}
+}
+
+// Resolve retorna os papéis do usuário na organização, diretos, dos grupos e
+// herdados, e as permissões concedidas por eles, sem duplicatas nem permissões
+// cobertas por outras. O usuário deve ter sido carregado como membro da
+// organização (UserRepository.FindMember).
+func (r *AccessResolver) Resolve(orgID uuid.UUID, user *models.User) ([]string, []string, error) {
+	var ids []uuid.UUID
+	for _, role := range user.Roles {
+		ids = append(ids, role.ID)
+	}
+	for _, group := range user.Groups {
+		for _, role := range group.Roles {
+			ids = append(ids, role.ID)
+		}
+	}
+	return r.resolveRoles(orgID, ids)
+}
+
+// ResolveGroups retorna os papéis e permissões de quem tem os papéis diretos
+// informados e participa diretamente dos grupos informados, herdando os papéis
+// dos grupos ancestrais. Permite calcular o acesso de um conjunto de grupos
+// diferente do atual (simulações).
+func (r *AccessResolver) ResolveGroups(orgID uuid.UUID, directRoles []models.Role, groupIDs []uuid.UUID) ([]string, []string, error) {
+	var ids []uuid.UUID
+	for _, role := range directRoles {
+		ids = append(ids, role.ID)
+	}
+
+	granted, err := r.groupRepo.FindGrantedRoleIDs(orgID, groupIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.resolveRoles(orgID, append(ids, granted...))
+}
+
+// ResolveOn retorna os papéis e as permissões concedidos ao usuário apenas sobre
+// o recurso, pelas atribuições com escopo feitas a ele ou aos seus grupos
+// (incluindo os grupos ancestrais). Os papéis globais não são incluídos.
+func (r *AccessResolver) ResolveOn(orgID uuid.UUID, user *models.User, resourceType, resourceID string) ([]string, []string, error) {
+	var groupIDs []uuid.UUID
+	for _, group := range user.Groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	ids, err := r.bindingRepo.FindRoleIDs(orgID, user.ID, groupIDs, resourceType, resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.resolveRoles(orgID, ids)
+}
+
+// resolveRoles expande os papéis com os papéis dos quais herdam e retorna seus
+// nomes e as permissões concedidas, sem duplicatas nem permissões cobertas por outras
+func (r *AccessResolver) resolveRoles(orgID uuid.UUID, ids []uuid.UUID) ([]string, []string, error) {
+	if len(ids) == 0 {
+		return []string{}, []string{}, nil
+	}
+
+	effective, err := r.roleRepo.FindWithAncestors(orgID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles := []string{}
+	permissions := []string{}
+	for _, role := range effective {
+		roles = append(roles, role.Name)
+		permissions = append(permissions, role.Permissions...)
+	}
+	sort.Strings(roles)
+	return roles, permission.Normalize(permissions), nil
+}
+
+// UserResponse monta o perfil do usuário com seus grupos e o acesso efetivo na
+// organização. Sem organização (uuid.Nil), o perfil não tem grupos nem papéis.
+func (r *AccessResolver) UserResponse(orgID uuid.UUID, user *models.User) (models.UserResponse, error) {
+	roles, permissions, err := r.Resolve(orgID, user)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	groups := []string{}
+	for _, group := range user.Groups {
+		groups = append(groups, group.Name)
+	}
+
+	response := models.UserResponse{
+		ID:          user.ID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Picture:     user.Picture,
+		Groups:      groups,
+		Roles:       roles,
+		Permissions: permissions,
+		Attributes:  user.Attributes,
+	}
+	if orgID != uuid.Nil {
+		response.OrganizationID = &orgID
+	}
+	return response, nil
+}
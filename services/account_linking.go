@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/providers"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReauthMaxAge define há quanto tempo o usuário pode ter se autenticado no
+// provedor para executar operações sensíveis, como vincular uma nova identidade
+const ReauthMaxAge = 5 * time.Minute
+
+var (
+	// ErrReauthRequired indica que a operação exige uma autenticação recente
+	ErrReauthRequired = errors.New("autenticação recente necessária: entre novamente antes de continuar")
+	// ErrIdentityLinked indica que a identidade externa já pertence a outro usuário
+	ErrIdentityLinked = errors.New("esta identidade já está vinculada a outra conta")
+	// ErrIdentityNotFound indica que a identidade não existe ou não pertence ao usuário
+	ErrIdentityNotFound = errors.New("identidade não encontrada")
+	// ErrLastIdentity impede remover a única forma de login do usuário
+	ErrLastIdentity = errors.New("não é possível desvincular a última identidade da conta")
+)
+
+// StartIdentityLink inicia o fluxo de vínculo de uma nova identidade à conta do
+// usuário autenticado. authTime é o instante do último login no provedor.
+func (s *AuthService) StartIdentityLink(userID, providerName, returnTo string, authTime time.Time) (string, string, error) {
+	if time.Since(authTime) > ReauthMaxAge {
+		return "", "", ErrReauthRequired
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.startLogin(providerName, returnTo, &id, nil)
+}
+
+// ListIdentities lista as identidades externas vinculadas ao usuário
+func (s *AuthService) ListIdentities(userID string) ([]models.UserIdentity, error) {
+	return s.identityRepo.ListByUser(userID)
+}
+
+// UnlinkIdentity remove uma identidade vinculada, preservando ao menos uma forma de login
+func (s *AuthService) UnlinkIdentity(userID, identityID string) error {
+	identities, err := s.identityRepo.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		if identity.ID.String() != identityID {
+			continue
+		}
+		if len(identities) == 1 {
+			return ErrLastIdentity
+		}
+		return s.identityRepo.Delete(&identity)
+	}
+
+	return ErrIdentityNotFound
+}
+
+// linkIdentity vincula a identidade externa ao usuário informado
+func (s *AuthService) linkIdentity(userID string, identity *providers.Identity) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	linked, err := s.identityRepo.FindByProviderSubject(identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		if linked.UserID != user.ID {
+			return nil, ErrIdentityLinked
+		}
+		linked.Email = identity.Email
+		linked.EmailVerified = identity.EmailVerified
+		linked.LastLoginAt = &now
+		return user, s.identityRepo.Update(linked)
+	}
+
+	err = s.identityRepo.Create(&models.UserIdentity{
+		UserID:        user.ID,
+		Provider:      identity.Provider,
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		LastLoginAt:   &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// hasVerifiedEmail verifica se alguma identidade do usuário comprovou o e-mail informado
+func (s *AuthService) hasVerifiedEmail(userID, email string) bool {
+	identities, err := s.identityRepo.ListByUser(userID)
+	if err != nil {
+		return false
+	}
+
+	for _, identity := range identities {
+		if identity.EmailVerified && strings.EqualFold(identity.Email, email) {
+			return true
+		}
+	}
+	return false
+}
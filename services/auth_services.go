@@ -2,215 +2,317 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"go-google/config"
 	"go-google/models"
+	"go-google/providers"
 	"go-google/repository"
-	"io"
-	"net/http"
-	"net/url"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/oauth2"
-	// "github.com/google/uuid"
+	"github.com/google/uuid"
 )
 
+// ErrEmailInUse indica que o e-mail da identidade externa já pertence a outro usuário
+var ErrEmailInUse = errors.New("já existe uma conta com este e-mail vinculada a outro provedor")
+
 // AuthService manipula a lógica de negócio relacionada à autenticação
 type AuthService struct {
-	config    *config.Config
-	userRepo  *repository.UserRepository
-	groupRepo *repository.GroupRepository
+	config        *config.Config
+	userRepo      *repository.UserRepository
+	organizations *OrganizationService
+	invitations   *InvitationService
+	stateRepo     *repository.LoginStateRepository
+	identityRepo  *repository.IdentityRepository
+	refreshRepo   *repository.RefreshTokenRepository
+	handoffRepo   *repository.LoginHandoffRepository
+	revocations   *RevocationService
+	keys          *KeyService
+	providers     *providers.Registry
+	access        *AccessResolver
 }
 
 // NewAuthService cria um novo serviço de autenticação
-func NewAuthService(config *config.Config, userRepo *repository.UserRepository, groupRepo *repository.GroupRepository) *AuthService {
+func NewAuthService(config *config.Config, userRepo *repository.UserRepository, organizations *OrganizationService, invitations *InvitationService, stateRepo *repository.LoginStateRepository, identityRepo *repository.IdentityRepository, refreshRepo *repository.RefreshTokenRepository, handoffRepo *repository.LoginHandoffRepository, revocations *RevocationService, keys *KeyService, providerRegistry *providers.Registry, access *AccessResolver) *AuthService {
 	return &AuthService{
-		config:    config,
-		userRepo:  userRepo,
-		groupRepo: groupRepo,
+		config:        config,
+		userRepo:      userRepo,
+		organizations: organizations,
+		invitations:   invitations,
+		stateRepo:     stateRepo,
+		identityRepo:  identityRepo,
+		refreshRepo:   refreshRepo,
+		handoffRepo:   handoffRepo,
+		revocations:   revocations,
+		keys:          keys,
+		providers:     providerRegistry,
+		access:        access,
 	}
 }
 
-// GetGoogleAuthURL retorna a URL para iniciar o fluxo de autenticação com Google
-func (s *AuthService) GetGoogleAuthURL() string {
-	googleConfig := config.GetGoogleOAuthConfig(s.config)
-	return googleConfig.AuthCodeURL("state", GetAuthURLOptions()...)
+// ListProviders retorna os nomes dos provedores de identidade habilitados
+func (s *AuthService) ListProviders() []string {
+	return s.providers.Names()
+}
+
+// GetAuthURL retorna a URL para iniciar o fluxo de autenticação com o provedor
+// informado junto com o state emitido, que deve ser vinculado ao navegador do usuário.
+// Com o token de um link de convite, o convite é aceito ao final do login.
+func (s *AuthService) GetAuthURL(providerName, returnTo, invitationToken string) (string, string, error) {
+	var invitationID *uuid.UUID
+	if invitationToken != "" {
+		invitation, err := s.invitations.Resolve(invitationToken)
+		if err != nil {
+			return "", "", err
+		}
+		invitationID = &invitation.ID
+	}
+	return s.startLogin(providerName, returnTo, nil, invitationID)
 }
 
-// ProcessGoogleCallback processa o callback do Google OAuth
-func (s *AuthService) ProcessGoogleCallback(code string) (*models.UserWithToken, error) {
-	// Trocar código por token
-	googleConfig := config.GetGoogleOAuthConfig(s.config)
-	token, err := googleConfig.Exchange(context.Background(), code)
+// startLogin emite o state e monta a URL de autorização. Quando linkUserID é
+// informado, o callback vincula a identidade a esse usuário em vez de fazer login.
+func (s *AuthService) startLogin(providerName, returnTo string, linkUserID, invitationID *uuid.UUID) (string, string, error) {
+	provider, err := s.providers.Get(providerName)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao trocar código por token: %w", err)
+		return "", "", err
 	}
 
-	// Buscar informações do usuário do Google
-	userInfo, err := s.fetchGoogleUserInfo(token.AccessToken)
+	state, loginState, err := s.issueLoginState(provider.Name(), returnTo, linkUserID, invitationID)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
-	// Verificar se o usuário já existe
-	user, err := s.userRepo.FindByGoogleID(userInfo.ID)
+	authURL, err := provider.AuthCodeURL(context.Background(), authRequest(state, loginState))
 	if err != nil {
-		return nil, err
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// SecureCookies indica se os cookies emitidos devem ter a flag Secure
+func (s *AuthService) SecureCookies() bool {
+	return strings.HasPrefix(s.config.GoogleRedirectURL, "https://")
+}
+
+// CookieSessions indica se a sessão é mantida em cookies HttpOnly
+func (s *AuthService) CookieSessions() bool {
+	return s.config.CookieSessions()
+}
+
+// ProcessCallback processa o callback do provedor informado, validando o state
+// recebido contra o state vinculado ao navegador. Retorna também o caminho de
+// retorno solicitado no início do login.
+func (s *AuthService) ProcessCallback(providerName, code, state, boundState string, client models.ClientInfo) (*models.UserWithToken, string, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	loginState, err := s.consumeLoginState(provider.Name(), state, boundState)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Carregar papéis padrão
-	defaultRoles, err := s.groupRepo.FindOrCreateDefaultRoles()
+	userWithToken, err := s.completeLogin(provider, code, loginState, client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return userWithToken, loginState.ReturnTo, nil
+}
+
+// completeLogin troca o código de autorização e cria ou atualiza o usuário
+func (s *AuthService) completeLogin(provider providers.IdentityProvider, code string, loginState *models.LoginState, client models.ClientInfo) (*models.UserWithToken, error) {
+	identity, err := provider.Exchange(context.Background(), code, authRequest("", loginState))
 	if err != nil {
 		return nil, err
 	}
 
-	// Criar ou atualizar usuário
-	if user == nil {
-		// Novo usuário
-		user = &models.User{
-			GoogleID: userInfo.ID,
-			Email:    userInfo.Email,
-			Name:     userInfo.Name,
-			Picture:  userInfo.Picture,
-			Roles:    []models.Role{defaultRoles[models.RoleUser]},
-		}
+	if err := s.checkSignInPolicy(identity); err != nil {
+		return nil, err
+	}
 
-		// se for o primeiro usuário, atribui role admin
-		existingUsers, _ := s.userRepo.ListAll()
-		if len(existingUsers) == 0 {
-			user.Roles = []models.Role{defaultRoles[models.RoleAdmin]}
-		} else {
-			user.Roles = []models.Role{defaultRoles[models.RoleUser]}
+	var invitation *models.Invitation
+	if loginState.InvitationID != nil && loginState.LinkUserID == nil {
+		invitation, err = s.invitations.ForLogin(*loginState.InvitationID, identity)
+		if err != nil {
+			return nil, err
 		}
-		
-		if err := s.userRepo.Create(user); err != nil {
+	}
+
+	var user *models.User
+	if loginState.LinkUserID != nil {
+		user, err = s.linkIdentity(loginState.LinkUserID.String(), identity)
+	} else {
+		user, err = s.resolveUser(identity, invitation != nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A sessão começa na organização do convite ou na mais antiga do usuário
+	var orgID uuid.UUID
+	if invitation != nil {
+		if err := s.invitations.Accept(invitation, user.ID); err != nil {
 			return nil, err
 		}
+		orgID = invitation.OrganizationID
 	} else {
-		// Atualizar usuário existente
-		user.Email = userInfo.Email
-		user.Name = userInfo.Name
-		user.Picture = userInfo.Picture
-		if err := s.userRepo.Update(user); err != nil {
+		orgID, err = s.organizations.DefaultOrganization(user.ID)
+		if err != nil {
 			return nil, err
 		}
 	}
 
-	// Gerar tokens JWT
-	accessToken, refreshToken, expiresIn, err := s.generateTokens(user)
+	return s.issueSession(user, orgID, newTokenSession(client))
+}
+
+// resolveUser localiza o usuário pela identidade vinculada (provedor + subject),
+// criando o usuário e a identidade no primeiro login. Convidados são cadastrados
+// mesmo no modo somente convidados e entram apenas na organização do convite.
+func (s *AuthService) resolveUser(identity *providers.Identity, invited bool) (*models.User, error) {
+	linked, err := s.identityRepo.FindByProviderSubject(identity.Provider, identity.Subject)
 	if err != nil {
 		return nil, err
 	}
 
-	// Preparar resposta
-	userResponse := models.UserResponse{
-		ID:       user.ID,
-		Email:    user.Email,
-		Name:     user.Name,
-		Picture:  user.Picture,
-		Groups:   []string{},
-		Roles:    []string{},
-		Permissions: []string{},
-	}
+	now := time.Now()
+	if linked != nil {
+		// Atualizar usuário existente
+		user, err := s.userRepo.FindByID(linked.UserID.String())
+		if err != nil {
+			return nil, err
+		}
+		user.Name = identity.Name
+		user.Picture = identity.Picture
+		if err := s.syncAccountEmail(user, linked, identity); err != nil {
+			return nil, err
+		}
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
 
-	// Adicionar grupos
-	for _, group := range user.Groups {
-		userResponse.Groups = append(userResponse.Groups, group.Name)
+		linked.Email = identity.Email
+		linked.EmailVerified = identity.EmailVerified
+		linked.LastLoginAt = &now
+		if err := s.identityRepo.Update(linked); err != nil {
+			return nil, err
+		}
+		return user, nil
 	}
 
-	// Adicionar papéis diretos e papéis dos grupos
-	roleMap := make(map[string]bool)
-	permMap := make(map[string]bool)
-
-	// Papéis diretos
-	for _, role := range user.Roles {
-		roleMap[role.Name] = true
-		for _, perm := range role.Permissions {
-			permMap[perm] = true
+	if identity.Email == "" {
+		return nil, errors.New("o provedor de identidade não retornou um e-mail")
+	}
+	existing, err := s.userRepo.FindByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		// Só vincula automaticamente quando ambos os lados comprovaram o e-mail;
+		// caso contrário o usuário precisa entrar na conta existente e vincular manualmente
+		if !identity.EmailVerified || !s.hasVerifiedEmail(existing.ID.String(), identity.Email) {
+			return nil, ErrEmailInUse
 		}
+		return s.linkIdentity(existing.ID.String(), identity)
 	}
 
-	// Papéis dos grupos
-	for _, group := range user.Groups {
-		for _, role := range group.Roles {
-			roleMap[role.Name] = true
-			for _, perm := range role.Permissions {
-				permMap[perm] = true
-			}
+	if !invited {
+		if err := s.checkInviteOnly(identity); err != nil {
+			return nil, err
 		}
 	}
 
-	// Converter mapas em slices
-	for role := range roleMap {
-		userResponse.Roles = append(userResponse.Roles, role)
+	// Novo usuário
+	user := &models.User{
+		Email:   identity.Email,
+		Name:    identity.Name,
+		Picture: identity.Picture,
+		Identities: []models.UserIdentity{{
+			Provider:      identity.Provider,
+			Subject:       identity.Subject,
+			Email:         identity.Email,
+			EmailVerified: identity.EmailVerified,
+			LastLoginAt:   &now,
+		}},
 	}
-	for perm := range permMap {
-		userResponse.Permissions = append(userResponse.Permissions, perm)
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
 	}
 
-	return &models.UserWithToken{
-		User:         userResponse,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    expiresIn,
-	}, nil
-}
+	if invited {
+		return user, nil
+	}
 
-// GoogleUserInfo representa as informações do usuário retornadas pela API do Google
-type GoogleUserInfo struct {
-	ID      string `json:"id"`
-	Email   string `json:"email"`
-	Name    string `json:"name"`
-	Picture string `json:"picture"`
+	// O novo usuário administra apenas a própria organização
+	if _, err := s.organizations.CreatePersonalOrganization(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-// fetchGoogleUserInfo busca informações do usuário do Google
-func (s *AuthService) fetchGoogleUserInfo(accessToken string) (*GoogleUserInfo, error) {
-	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + accessToken)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar informações do usuário: %w", err)
+// syncAccountEmail acompanha a troca de e-mail no provedor: o e-mail da conta
+// passa a ser o novo e-mail verificado da identidade que tinha o e-mail da conta.
+// O e-mail de outra identidade vinculada, ou já usado por outra conta, não é adotado.
+func (s *AuthService) syncAccountEmail(user *models.User, linked *models.UserIdentity, identity *providers.Identity) error {
+	if identity.Email == "" || !identity.EmailVerified || strings.EqualFold(identity.Email, user.Email) {
+		return nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("resposta não-OK da API do Google: %s", resp.Status)
+	if !strings.EqualFold(linked.Email, user.Email) {
+		return nil
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	other, err := s.userRepo.FindByEmail(identity.Email)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao ler resposta: %w", err)
+		return err
 	}
-
-	var userInfo GoogleUserInfo
-	if err := json.Unmarshal(data, &userInfo); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+	if other != nil && other.ID != user.ID {
+		log.Printf("E-mail %s do provedor %s já pertence a outra conta; mantendo %s no usuário %s", identity.Email, identity.Provider, user.Email, user.ID)
+		return nil
 	}
 
-	return &userInfo, nil
+	user.Email = identity.Email
+	return nil
 }
 
-// RefreshToken atualiza o token de acesso usando um token de atualização
-func (s *AuthService) RefreshToken(refreshToken string) (*models.UserWithToken, error) {
-	// Verificar token de atualização
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("método de assinatura inválido")
-		}
-		return []byte(s.config.JWTSecret), nil
-	})
+// authRequest monta os parâmetros do login a partir do state persistido
+func authRequest(state string, loginState *models.LoginState) providers.AuthRequest {
+	return providers.AuthRequest{
+		State:        state,
+		CodeVerifier: loginState.CodeVerifier,
+		Nonce:        loginState.Nonce,
+	}
+}
 
-	if err != nil || !token.Valid {
-		return nil, errors.New("token de atualização inválido")
+// RefreshToken atualiza o token de acesso usando um token de atualização. Cada
+// token só pode ser usado uma vez: a renovação o rotaciona, e reapresentar um
+// token já rotacionado revoga toda a família (sessão). A sessão continua na
+// mesma organização enquanto o usuário participar dela.
+func (s *AuthService) RefreshToken(refreshToken string, client models.ClientInfo) (*models.UserWithToken, error) {
+	return s.renew(refreshToken, nil, client)
+}
+
+// SwitchOrganization troca a organização ativa da sessão, rotacionando o token
+// de atualização e emitindo tokens com a nova organização
+func (s *AuthService) SwitchOrganization(refreshToken, organizationID string, client models.ClientInfo) (*models.UserWithToken, error) {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return nil, ErrNotOrganizationMember
 	}
+	return s.renew(refreshToken, &orgID, client)
+}
 
-	// Extrair claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("falha ao extrair claims")
+// renew valida e rotaciona o token de atualização e emite novos tokens na mesma
+// família, na organização informada ou, sem ela, na organização do token
+func (s *AuthService) renew(refreshToken string, target *uuid.UUID, client models.ClientInfo) (*models.UserWithToken, error) {
+	// Verificar token de atualização
+	claims, err := s.parseToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
 	}
 
 	userID, ok := claims["sub"].(string)
@@ -224,170 +326,185 @@ func (s *AuthService) RefreshToken(refreshToken string) (*models.UserWithToken,
 		return nil, errors.New("tipo de token inválido")
 	}
 
-	// Buscar usuário
-	user, err := s.userRepo.FindByID(userID)
+	// Conferir o token persistido
+	stored, err := s.refreshRepo.FindByHash(hashToken(refreshToken))
 	if err != nil {
 		return nil, err
 	}
-
-	// Gerar novos tokens
-	accessToken, newRefreshToken, expiresIn, err := s.generateTokens(user)
-	if err != nil {
-		return nil, err
+	if stored == nil || stored.UserID.String() != userID || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.RotatedAt != nil {
+		return nil, s.handleRefreshReuse(stored)
 	}
 
-	// Preparar resposta
-	userResponse := models.UserResponse{
-		ID:       user.ID,
-		Email:    user.Email,
-		Name:     user.Name,
-		Picture:  user.Picture,
-		Groups:   []string{},
-		Roles:    []string{},
-		Permissions: []string{},
+	// A troca de organização é validada antes de consumir o token
+	orgID := organizationClaim(claims)
+	if target != nil {
+		member, err := s.organizations.IsMember(*target, stored.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, ErrNotOrganizationMember
+		}
+		orgID = *target
 	}
 
-	// Adicionar grupos
-	for _, group := range user.Groups {
-		userResponse.Groups = append(userResponse.Groups, group.Name)
+	// A renovação preserva o instante da última autenticação no provedor
+	var authTime time.Time
+	if value, ok := claims["auth_time"].(float64); ok {
+		authTime = time.Unix(int64(value), 0)
 	}
 
-	// Adicionar papéis diretos e papéis dos grupos
-	roleMap := make(map[string]bool)
-	permMap := make(map[string]bool)
+	// Buscar usuário
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Papéis diretos
-	for _, role := range user.Roles {
-		roleMap[role.Name] = true
-		for _, perm := range role.Permissions {
-			permMap[perm] = true
+	// Quem saiu da organização do token volta para a organização padrão
+	if target == nil && orgID != uuid.Nil {
+		member, err := s.organizations.IsMember(orgID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			if orgID, err = s.organizations.DefaultOrganization(user.ID); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// Papéis dos grupos
-	for _, group := range user.Groups {
-		for _, role := range group.Roles {
-			roleMap[role.Name] = true
-			for _, perm := range role.Permissions {
-				permMap[perm] = true
-			}
+	// Gerar novos tokens na mesma família; o token apresentado é rotacionado ao
+	// gravar o sucessor
+	session := tokenSession{familyID: stored.FamilyID, authTime: authTime, client: client, replaces: &stored.ID}
+	result, err := s.issueSession(user, orgID, session)
+	if errors.Is(err, errRefreshTokenRotated) {
+		// Outra requisição usou o mesmo token ao mesmo tempo
+		return nil, s.handleRefreshReuse(stored)
+	}
+	return result, err
+}
+
+// issueSession gera os tokens da sessão na organização e monta a resposta com o
+// acesso efetivo do usuário nela (incluindo papéis herdados)
+func (s *AuthService) issueSession(user *models.User, orgID uuid.UUID, session tokenSession) (*models.UserWithToken, error) {
+	if orgID != uuid.Nil {
+		member, err := s.userRepo.FindMember(orgID, user.ID.String())
+		if err != nil {
+			return nil, err
 		}
+		user = member
 	}
 
-	// Converter mapas em slices
-	for role := range roleMap {
-		userResponse.Roles = append(userResponse.Roles, role)
+	accessToken, refreshToken, expiresIn, err := s.generateTokens(user, orgID, session)
+	if err != nil {
+		return nil, err
 	}
-	for perm := range permMap {
-		userResponse.Permissions = append(userResponse.Permissions, perm)
+
+	userResponse, err := s.access.UserResponse(orgID, user)
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.UserWithToken{
 		User:         userResponse,
 		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
+		RefreshToken: refreshToken,
 		ExpiresIn:    expiresIn,
 	}, nil
 }
 
-// generateTokens gera tokens JWT para o usuário
-func (s *AuthService) generateTokens(user *models.User) (accessToken string, refreshToken string, expiresIn int64, err error) {
-	// Calcular duração dos tokens
-	accessTokenExpiry := time.Now().Add(15 * time.Minute)
-	refreshTokenExpiry := time.Now().Add(7 * 24 * time.Hour)
-	expiresIn = int64(accessTokenExpiry.Sub(time.Now()).Seconds())
-
-	// Coletar papéis e permissões
-	roles := []string{}
-	permissions := []string{}
+// organizationClaim retorna a organização ativa gravada no token, ou uuid.Nil
+func organizationClaim(claims jwt.MapClaims) uuid.UUID {
+	value, _ := claims["org_id"].(string)
+	return parseOrganizationID(value)
+}
 
-	// Papéis diretos
-	for _, role := range user.Roles {
-		roles = append(roles, role.Name)
-		permissions = append(permissions, role.Permissions...)
+// parseToken valida a assinatura e a expiração de um token emitido pelo serviço
+func (s *AuthService) parseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, s.keys.Keyfunc)
+	if err != nil || !token.Valid {
+		return nil, errors.New("token inválido")
 	}
 
-	// Papéis dos grupos
-	for _, group := range user.Groups {
-		for _, role := range group.Roles {
-			roles = append(roles, role.Name)
-			permissions = append(permissions, role.Permissions...)
-		}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("falha ao extrair claims")
 	}
+	return claims, nil
+}
 
-	// Remover duplicatas
-	uniqueRoles := make(map[string]bool)
-	uniquePermissions := make(map[string]bool)
+// generateTokens gera tokens JWT para o usuário na organização ativa e persiste
+// o token de atualização na família da sessão informada
+func (s *AuthService) generateTokens(user *models.User, orgID uuid.UUID, session tokenSession) (accessToken string, refreshToken string, expiresIn int64, err error) {
+	// Calcular duração dos tokens a partir do instante de emissão, posterior a
+	// qualquer logout-all do usuário
+	issuedAt := s.revocations.IssuedAt(user.ID.String())
+	accessTokenExpiry := issuedAt.Add(AccessTokenTTL)
+	refreshTokenExpiry := issuedAt.Add(RefreshTokenTTL)
+	expiresIn = int64(AccessTokenTTL.Seconds())
+
+	// Coletar papéis e permissões efetivos, incluindo os herdados
+	finalRoles, finalPermissions, err := s.access.Resolve(orgID, user)
+	if err != nil {
+		return "", "", 0, err
+	}
 
-	var finalRoles []string
-	var finalPermissions []string
+	// Gerar token de acesso
+	accessClaims := jwt.MapClaims{
+		"sub":       user.ID.String(),
+		"jti":       uuid.New().String(),
+		"email":     user.Email,
+		"name":      user.Name,
+		"exp":       accessTokenExpiry.Unix(),
+		"iat":       issuedAt.Unix(),
+		"auth_time": session.authTime.Unix(),
+		"sid":       session.familyID.String(),
+		"type":      "access",
+	}
 
-	for _, role := range roles {
-		if !uniqueRoles[role] {
-			uniqueRoles[role] = true
-			finalRoles = append(finalRoles, role)
-		}
+	// Na autorização em tempo real, as permissões são resolvidas no banco pelo
+	// middleware e não são gravadas no token, onde ficariam desatualizadas
+	if !s.config.LiveAuthz() {
+		accessClaims["roles"] = finalRoles
+		accessClaims["permissions"] = finalPermissions
 	}
 
-	for _, perm := range permissions {
-		if !uniquePermissions[perm] {
-			uniquePermissions[perm] = true
-			finalPermissions = append(finalPermissions, perm)
-		}
+	if orgID != uuid.Nil {
+		accessClaims["org_id"] = orgID.String()
 	}
 
-	// Gerar token de acesso
-	accessClaims := jwt.MapClaims{
-		"sub":         user.ID.String(),
-		"email":       user.Email,
-		"name":        user.Name,
-		"roles":       finalRoles,
-		"permissions": finalPermissions,
-		"exp":         accessTokenExpiry.Unix(),
-		"iat":         time.Now().Unix(),
-		"type":        "access",
-	}
-
-	accessJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessJWT.SignedString([]byte(s.config.JWTSecret))
+	accessToken, err = s.keys.Sign(accessClaims)
 	if err != nil {
 		return "", "", 0, err
 	}
 
 	// Gerar token de atualização
+	refreshTokenID := uuid.New()
 	refreshClaims := jwt.MapClaims{
-		"sub":  user.ID.String(),
-		"exp":  refreshTokenExpiry.Unix(),
-		"iat":  time.Now().Unix(),
-		"type": "refresh",
+		"sub":       user.ID.String(),
+		"jti":       refreshTokenID.String(),
+		"sid":       session.familyID.String(),
+		"exp":       refreshTokenExpiry.Unix(),
+		"iat":       issuedAt.Unix(),
+		"auth_time": session.authTime.Unix(),
+		"type":      "refresh",
 	}
 
-	refreshJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = refreshJWT.SignedString([]byte(s.config.JWTSecret))
+	if orgID != uuid.Nil {
+		refreshClaims["org_id"] = orgID.String()
+	}
+
+	refreshToken, err = s.keys.Sign(refreshClaims)
 	if err != nil {
 		return "", "", 0, err
 	}
 
-	return accessToken, refreshToken, expiresIn, nil
-}
+	if err := s.persistRefreshToken(refreshTokenID, user.ID, refreshToken, refreshTokenExpiry, session); err != nil {
+		return "", "", 0, err
+	}
 
-// GetFrontendRedirectURL gera a URL para redirecionar para o frontend com tokens
-func (s *AuthService) GetFrontendRedirectURL(accessToken, refreshToken string) string {
-	baseURL := s.config.FrontendURL
-	params := url.Values{}
-	params.Add("access_token", accessToken)
-	params.Add("refresh_token", refreshToken)
-	
-	if strings.Contains(baseURL, "?") {
-		return baseURL + "&" + params.Encode()
-	}
-	return baseURL + "?" + params.Encode()
+	return accessToken, refreshToken, expiresIn, nil
 }
-
-// GetAuthURLOptions retorna opções para a URL de autenticação do Google
-func GetAuthURLOptions() []oauth2.AuthCodeOption {
-	return []oauth2.AuthCodeOption{
-		oauth2.AccessTypeOffline,
-		oauth2.ApprovalForce,
-	}
-}
\ No newline at end of file
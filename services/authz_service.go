@@ -0,0 +1,382 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/permission"
+	"go-google/policy"
+	"go-google/repository"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// authzVersionCheckInterval define por quanto tempo as permissões em cache são
+// usadas sem consultar a versão de autorização do usuário no banco (propagação
+// entre instâncias)
+const authzVersionCheckInterval = 5 * time.Second
+
+// ErrInvalidAuthzCheck indica uma verificação sem critérios ou com recurso incompleto
+var ErrInvalidAuthzCheck = errors.New("verificação inválida: informe permission, role ou group, e resource_type junto com resource_id")
+
+// AuthzService resolve os papéis e permissões efetivos dos usuários em cada
+// organização a partir do banco, mantendo um cache por usuário e organização
+// invalidado pela versão de autorização
+type AuthzService struct {
+	userRepo authzMembers
+	access   authzResolver
+	policies authzPolicies
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]map[uuid.UUID]authzEntry
+}
+
+// authzMembers consulta a versão de autorização e os membros das organizações
+// (implementado por repository.UserRepository)
+type authzMembers interface {
+	FindAuthzVersion(id string) (version int64, found bool, err error)
+	FindMember(orgID uuid.UUID, id string) (*models.User, error)
+}
+
+// authzResolver resolve os papéis e permissões de um membro (implementado por AccessResolver)
+type authzResolver interface {
+	Resolve(orgID uuid.UUID, user *models.User) ([]string, []string, error)
+	ResolveOn(orgID uuid.UUID, user *models.User, resourceType, resourceID string) ([]string, []string, error)
+}
+
+// authzPolicies avalia as políticas de acesso da organização (implementado por PolicyService)
+type authzPolicies interface {
+	Evaluate(userID, organizationID, action string, resource, context policy.Attributes) (policy.Decision, error)
+}
+
+// authzEntry guarda os atributos, grupos, papéis e permissões resolvidos de um
+// usuário em uma organização e a versão de autorização em que foram calculados
+type authzEntry struct {
+	version     int64
+	member      bool
+	email       string
+	attributes  policy.Attributes
+	groups      []string
+	roles       []string
+	permissions []string
+	checkedAt   time.Time
+}
+
+// NewAuthzService cria um novo serviço de autorização
+func NewAuthzService(userRepo *repository.UserRepository, access *AccessResolver) *AuthzService {
+	return &AuthzService{
+		userRepo: userRepo,
+		access:   access,
+		now:      time.Now,
+		entries:  make(map[string]map[uuid.UUID]authzEntry),
+	}
+}
+
+// UsePolicies liga as políticas de acesso avaliadas nas verificações de
+// permissão. O PolicyService depende deste serviço para montar os atributos do
+// usuário, por isso é ligado depois de criado.
+func (s *AuthzService) UsePolicies(policies authzPolicies) {
+	s.policies = policies
+}
+
+// Resolve retorna os papéis e permissões efetivos do usuário na organização. Um
+// usuário removido ou que não participa da organização não tem papéis nem permissões.
+func (s *AuthzService) Resolve(userID, organizationID string) ([]string, []string, error) {
+	entry, _, err := s.lookup(userID, parseOrganizationID(organizationID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry.roles, entry.permissions, nil
+}
+
+// AllowedOn indica se as atribuições de papel com escopo no recurso concedem a
+// permissão ao usuário na organização. As permissões globais não são
+// consideradas, pois quem as verifica é o middleware. As atribuições não passam
+// pelo cache e são consultadas a cada chamada.
+func (s *AuthzService) AllowedOn(userID, organizationID, requiredPermission, resourceType, resourceID string) (bool, error) {
+	_, scoped, err := s.resolveOn(userID, parseOrganizationID(organizationID), resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+	return permission.Allowed(scoped, requiredPermission), nil
+}
+
+// Check avalia se o usuário atende, na organização, a todos os critérios da verificação:
+// permissão (global ou com escopo no recurso), papel (global ou com escopo) e
+// associação ao grupo (direta ou por um subgrupo). A permissão segue a
+// semântica deny-overrides das políticas de acesso: uma política de negação
+// aplicável nega mesmo com a permissão nos papéis, e uma de concessão permite
+// sem ela; a decisão traz o ID da política. A decisão negada traz o motivo do
+// primeiro critério não atendido.
+func (s *AuthzService) Check(req models.AuthzCheckRequest) (models.AuthzDecision, error) {
+	if err := validateAuthzCheck(req); err != nil {
+		return models.AuthzDecision{}, err
+	}
+
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		return models.AuthzDecision{Reason: models.DecisionUserNotFound}, nil
+	}
+	orgID := parseOrganizationID(req.OrganizationID)
+	entry, found, err := s.lookup(req.UserID, orgID)
+	if err != nil {
+		return models.AuthzDecision{}, err
+	}
+	if !found {
+		return models.AuthzDecision{Reason: models.DecisionUserNotFound}, nil
+	}
+	if !entry.member {
+		return models.AuthzDecision{Reason: models.DecisionNotOrganizationMember}, nil
+	}
+
+	// As atribuições com escopo só são consultadas quando os papéis globais não bastam
+	var scopedRoles, scopedPermissions []string
+	scopedLoaded := false
+	loadScoped := func() error {
+		if scopedLoaded || req.ResourceType == "" {
+			return nil
+		}
+		scopedLoaded = true
+		scopedRoles, scopedPermissions, err = s.resolveOn(req.UserID, orgID, req.ResourceType, req.ResourceID)
+		return err
+	}
+
+	reason := ""
+	allow := func(r string) {
+		if reason == "" {
+			reason = r
+		}
+	}
+
+	policyID := ""
+	if req.Permission != "" {
+		decision, err := s.evaluatePolicies(req)
+		if err != nil {
+			return models.AuthzDecision{}, err
+		}
+
+		switch {
+		case decision.Denied():
+			return models.AuthzDecision{Reason: models.DecisionPolicyDenied, PolicyID: decision.PolicyID}, nil
+		case decision.Allowed():
+			allow(models.DecisionPolicy)
+			policyID = decision.PolicyID
+		case permission.Allowed(entry.permissions, req.Permission):
+			allow(models.DecisionGlobalPermission)
+		default:
+			if err := loadScoped(); err != nil {
+				return models.AuthzDecision{}, err
+			}
+			if !permission.Allowed(scopedPermissions, req.Permission) {
+				return models.AuthzDecision{Reason: models.DecisionMissingPermission}, nil
+			}
+			allow(models.DecisionRoleBinding)
+		}
+	}
+
+	if req.Role != "" {
+		if slices.Contains(entry.roles, req.Role) {
+			allow(models.DecisionRole)
+		} else if err := loadScoped(); err != nil {
+			return models.AuthzDecision{}, err
+		} else if slices.Contains(scopedRoles, req.Role) {
+			allow(models.DecisionRoleBinding)
+		} else {
+			return models.AuthzDecision{Reason: models.DecisionMissingRole}, nil
+		}
+	}
+
+	if req.Group != "" {
+		if !slices.Contains(entry.groups, req.Group) {
+			return models.AuthzDecision{Reason: models.DecisionNotGroupMember}, nil
+		}
+		allow(models.DecisionGroupMember)
+	}
+
+	return models.AuthzDecision{Allowed: true, Reason: reason, PolicyID: policyID}, nil
+}
+
+// evaluatePolicies avalia as políticas da organização para a permissão
+// verificada. O recurso tem os atributos informados na verificação e, com
+// resource_type, type e id; o contexto tem os atributos informados e o time
+// deste serviço.
+func (s *AuthzService) evaluatePolicies(req models.AuthzCheckRequest) (policy.Decision, error) {
+	if s.policies == nil {
+		return policy.Decision{Effect: policy.NotApplicable}, nil
+	}
+
+	resource := policy.Attributes{}
+	for key, value := range req.Resource {
+		resource[key] = value
+	}
+	if req.ResourceType != "" {
+		resource["type"] = req.ResourceType
+		resource["id"] = req.ResourceID
+	}
+
+	context := policy.Attributes{}
+	for key, value := range req.Context {
+		context[key] = value
+	}
+	context["time"] = s.now()
+
+	return s.policies.Evaluate(req.UserID, req.OrganizationID, req.Permission, resource, context)
+}
+
+// Subject monta os atributos do usuário avaliados pelas políticas de acesso da
+// organização: os atributos personalizados na organização e, sobrepondo-os, id,
+// organization_id, email, groups, roles e permissions. Um usuário removido tem
+// apenas o id e a organização.
+func (s *AuthzService) Subject(userID string, orgID uuid.UUID) (policy.Attributes, error) {
+	subject := policy.Attributes{}
+	if _, err := uuid.Parse(userID); err != nil {
+		subject["id"] = userID
+		subject["organization_id"] = orgID.String()
+		return subject, nil
+	}
+
+	entry, found, err := s.lookup(userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range entry.attributes {
+		subject[key] = value
+	}
+	subject["id"] = userID
+	subject["organization_id"] = orgID.String()
+	if found {
+		subject["email"] = entry.email
+		subject["groups"] = entry.groups
+		subject["roles"] = entry.roles
+		subject["permissions"] = entry.permissions
+	}
+	return subject, nil
+}
+
+// CheckMany avalia várias verificações, retornando as decisões na mesma ordem.
+// Se alguma verificação for inválida, nenhuma é avaliada.
+func (s *AuthzService) CheckMany(reqs []models.AuthzCheckRequest) ([]models.AuthzDecision, error) {
+	for _, req := range reqs {
+		if err := validateAuthzCheck(req); err != nil {
+			return nil, err
+		}
+	}
+
+	decisions := make([]models.AuthzDecision, 0, len(reqs))
+	for _, req := range reqs {
+		decision, err := s.Check(req)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+// Invalidate descarta as permissões em cache dos usuários informados, em todas as organizações
+func (s *AuthzService) Invalidate(userIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, userID := range userIDs {
+		delete(s.entries, userID)
+	}
+}
+
+// InvalidateAll descarta todo o cache, usado quando uma alteração em grupos ou
+// papéis afeta um conjunto de usuários não conhecido de antemão
+func (s *AuthzService) InvalidateAll() {
+	s.mu.Lock()
+	s.entries = make(map[string]map[uuid.UUID]authzEntry)
+	s.mu.Unlock()
+}
+
+// lookup retorna o acesso resolvido do usuário na organização, usando o cache
+// enquanto a versão de autorização não mudar, e indica se o usuário existe. Quem
+// não participa da organização tem uma entrada sem papéis nem permissões.
+func (s *AuthzService) lookup(userID string, orgID uuid.UUID) (authzEntry, bool, error) {
+	s.mu.RLock()
+	entry, cached := s.entries[userID][orgID]
+	s.mu.RUnlock()
+	if cached && s.now().Sub(entry.checkedAt) < authzVersionCheckInterval {
+		return entry, true, nil
+	}
+
+	// A versão é lida antes das permissões, então o cache nunca associa
+	// permissões antigas a uma versão mais nova
+	version, found, err := s.userRepo.FindAuthzVersion(userID)
+	if err != nil {
+		return authzEntry{}, false, err
+	}
+	if !found {
+		s.Invalidate(userID)
+		return authzEntry{groups: []string{}, roles: []string{}, permissions: []string{}}, false, nil
+	}
+
+	if !cached || entry.version != version {
+		entry = authzEntry{version: version, groups: []string{}, roles: []string{}, permissions: []string{}}
+		user, err := s.userRepo.FindMember(orgID, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return authzEntry{}, false, err
+		}
+		if err == nil {
+			entry.roles, entry.permissions, err = s.access.Resolve(orgID, user)
+			if err != nil {
+				return authzEntry{}, false, err
+			}
+			entry.member = true
+			entry.email = user.Email
+			entry.attributes = user.Attributes
+			for _, group := range user.Groups {
+				entry.groups = append(entry.groups, group.Name)
+			}
+		}
+	}
+	entry.checkedAt = s.now()
+
+	s.mu.Lock()
+	if s.entries[userID] == nil {
+		s.entries[userID] = make(map[uuid.UUID]authzEntry)
+	}
+	s.entries[userID][orgID] = entry
+	s.mu.Unlock()
+	return entry, true, nil
+}
+
+// resolveOn carrega o usuário como membro da organização e resolve os papéis e
+// permissões das atribuições com escopo no recurso. Um usuário removido ou fora
+// da organização não tem atribuições.
+func (s *AuthzService) resolveOn(userID string, orgID uuid.UUID, resourceType, resourceID string) ([]string, []string, error) {
+	user, err := s.userRepo.FindMember(orgID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []string{}, []string{}, nil
+		}
+		return nil, nil, err
+	}
+	return s.access.ResolveOn(orgID, user, resourceType, resourceID)
+}
+
+// parseOrganizationID converte o ID da organização ativa, usando uuid.Nil (que
+// não corresponde a nenhuma organização) quando ele está ausente ou é inválido
+func parseOrganizationID(id string) uuid.UUID {
+	orgID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil
+	}
+	return orgID
+}
+
+// validateAuthzCheck exige ao menos um critério e o recurso completo, quando informado
+func validateAuthzCheck(req models.AuthzCheckRequest) error {
+	if strings.TrimSpace(req.Permission) == "" && strings.TrimSpace(req.Role) == "" && strings.TrimSpace(req.Group) == "" {
+		return ErrInvalidAuthzCheck
+	}
+	if (req.ResourceType == "") != (req.ResourceID == "") {
+		return ErrInvalidAuthzCheck
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"go-google/models"
+	"go-google/policy"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeAuthzMembers simula o repositório de usuários, contando as consultas
+type fakeAuthzMembers struct {
+	versions      map[string]int64
+	members       map[string]*models.User
+	versionReads  int
+	memberLookups int
+}
+
+func (f *fakeAuthzMembers) FindAuthzVersion(id string) (int64, bool, error) {
+	f.versionReads++
+	version, found := f.versions[id]
+	return version, found, nil
+}
+
+func (f *fakeAuthzMembers) FindMember(orgID uuid.UUID, id string) (*models.User, error) {
+	f.memberLookups++
+	user, found := f.members[id]
+	if !found {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+// fakeAuthzResolver resolve como papéis os nomes dos papéis diretos do usuário,
+// e como permissões as dos próprios papéis
+type fakeAuthzResolver struct{}
+
+func (fakeAuthzResolver) Resolve(orgID uuid.UUID, user *models.User) ([]string, []string, error) {
+	roles, permissions := []string{}, []string{}
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+		permissions = append(permissions, role.Permissions...)
+	}
+	return roles, permissions, nil
+}
+
+func (fakeAuthzResolver) ResolveOn(orgID uuid.UUID, user *models.User, resourceType, resourceID string) ([]string, []string, error) {
+	return []string{}, []string{}, nil
+}
+
+// testAuthz cria o serviço com um membro "viewer" e um relógio controlado pelo teste
+func testAuthz() (*AuthzService, *fakeAuthzMembers, *time.Time, string, uuid.UUID) {
+	userID := uuid.NewString()
+	orgID := uuid.New()
+	members := &fakeAuthzMembers{
+		versions: map[string]int64{userID: 1},
+		members: map[string]*models.User{
+			userID: {Roles: []models.Role{{Name: "viewer", Permissions: []string{"users:read"}}}},
+		},
+	}
+
+	now := time.Now()
+	service := &AuthzService{
+		userRepo: members,
+		access:   fakeAuthzResolver{},
+		now:      func() time.Time { return now },
+		entries:  make(map[string]map[uuid.UUID]authzEntry),
+	}
+	return service, members, &now, userID, orgID
+}
+
+// promote troca os papéis do membro por "editor" e incrementa a versão de autorização
+func promote(members *fakeAuthzMembers, userID string) {
+	members.members[userID] = &models.User{Roles: []models.Role{{Name: "editor", Permissions: []string{"users:write"}}}}
+	members.versions[userID]++
+}
+
+func cachedRoles(t *testing.T, service *AuthzService, userID string, orgID uuid.UUID) []string {
+	t.Helper()
+
+	roles, _, err := service.Resolve(userID, orgID.String())
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	return roles
+}
+
+func TestAuthzResolveUsesCacheWithinCheckInterval(t *testing.T) {
+	service, members, now, userID, orgID := testAuthz()
+
+	if roles := cachedRoles(t, service, userID, orgID); !slices.Equal(roles, []string{"viewer"}) {
+		t.Fatalf("papéis = %v, esperado [viewer]", roles)
+	}
+	promote(members, userID)
+
+	// Dentro do intervalo, a versão não é consultada e o cache prevalece
+	*now = now.Add(authzVersionCheckInterval - time.Second)
+	if roles := cachedRoles(t, service, userID, orgID); !slices.Equal(roles, []string{"viewer"}) {
+		t.Errorf("papéis = %v, esperado [viewer] do cache", roles)
+	}
+	if members.versionReads != 1 || members.memberLookups != 1 {
+		t.Errorf("consultas de versão = %d e de membro = %d, esperado 1 e 1", members.versionReads, members.memberLookups)
+	}
+}
+
+func TestAuthzResolveReloadsWhenVersionChanges(t *testing.T) {
+	service, members, now, userID, orgID := testAuthz()
+	cachedRoles(t, service, userID, orgID)
+
+	promote(members, userID)
+	*now = now.Add(authzVersionCheckInterval)
+
+	if roles := cachedRoles(t, service, userID, orgID); !slices.Equal(roles, []string{"editor"}) {
+		t.Errorf("papéis = %v, esperado [editor]", roles)
+	}
+	if members.versionReads != 2 || members.memberLookups != 2 {
+		t.Errorf("consultas de versão = %d e de membro = %d, esperado 2 e 2", members.versionReads, members.memberLookups)
+	}
+}
+
+func TestAuthzResolveKeepsCacheWhenVersionIsUnchanged(t *testing.T) {
+	service, members, now, userID, orgID := testAuthz()
+	cachedRoles(t, service, userID, orgID)
+
+	// Passado o intervalo, a versão é conferida, mas o membro não é recarregado
+	*now = now.Add(authzVersionCheckInterval)
+	cachedRoles(t, service, userID, orgID)
+	if members.versionReads != 2 || members.memberLookups != 1 {
+		t.Errorf("consultas de versão = %d e de membro = %d, esperado 2 e 1", members.versionReads, members.memberLookups)
+	}
+
+	// O intervalo recomeça a partir da última conferência
+	*now = now.Add(authzVersionCheckInterval - time.Second)
+	cachedRoles(t, service, userID, orgID)
+	if members.versionReads != 2 {
+		t.Errorf("consultas de versão = %d, esperado 2", members.versionReads)
+	}
+}
+
+func TestAuthzInvalidateSkipsCheckInterval(t *testing.T) {
+	service, members, _, userID, orgID := testAuthz()
+	cachedRoles(t, service, userID, orgID)
+
+	promote(members, userID)
+	service.Invalidate(userID)
+
+	if roles := cachedRoles(t, service, userID, orgID); !slices.Equal(roles, []string{"editor"}) {
+		t.Errorf("papéis = %v, esperado [editor] após Invalidate", roles)
+	}
+}
+
+func TestAuthzCachesPerOrganization(t *testing.T) {
+	service, members, _, userID, orgID := testAuthz()
+	cachedRoles(t, service, userID, orgID)
+
+	if _, _, err := service.Resolve(userID, uuid.NewString()); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if members.memberLookups != 2 {
+		t.Errorf("consultas de membro = %d, esperado 2 (uma por organização)", members.memberLookups)
+	}
+}
+
+func TestAuthzCheckRemovedAndNonMemberUsers(t *testing.T) {
+	service, members, now, userID, orgID := testAuthz()
+	outsiderID := uuid.NewString()
+	members.versions[outsiderID] = 1
+
+	check := func(userID string) models.AuthzDecision {
+		t.Helper()
+		decision, err := service.Check(models.AuthzCheckRequest{UserID: userID, OrganizationID: orgID.String(), Permission: "users:read"})
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		return decision
+	}
+
+	if decision := check(userID); !decision.Allowed {
+		t.Fatalf("decisão = %+v, esperado permitido", decision)
+	}
+	if decision := check(outsiderID); decision.Allowed || decision.Reason != models.DecisionNotOrganizationMember {
+		t.Errorf("decisão = %+v, esperado %s", decision, models.DecisionNotOrganizationMember)
+	}
+
+	// Um usuário removido perde o acesso assim que a versão é conferida
+	delete(members.versions, userID)
+	*now = now.Add(authzVersionCheckInterval)
+	if decision := check(userID); decision.Allowed || decision.Reason != models.DecisionUserNotFound {
+		t.Errorf("decisão = %+v, esperado %s", decision, models.DecisionUserNotFound)
+	}
+	if _, cached := service.entries[userID]; cached {
+		t.Error("o cache do usuário removido deveria ter sido descartado")
+	}
+}
+
+// fakeAuthzPolicies avalia as políticas informadas com os atributos do sujeito do serviço
+type fakeAuthzPolicies struct {
+	service *AuthzService
+	rules   []policy.Policy
+}
+
+func (f fakeAuthzPolicies) Evaluate(userID, organizationID, action string, resource, context policy.Attributes) (policy.Decision, error) {
+	subject, err := f.service.Subject(userID, parseOrganizationID(organizationID))
+	if err != nil {
+		return policy.Decision{}, err
+	}
+	return policy.Evaluate(f.rules, policy.Request{Action: action, Subject: subject, Resource: resource, Context: context}), nil
+}
+
+func TestAuthzCheckEvaluatesPolicies(t *testing.T) {
+	service, _, _, userID, orgID := testAuthz()
+	service.UsePolicies(fakeAuthzPolicies{service: service, rules: []policy.Policy{
+		{ID: "nega-norte", Effect: policy.EffectDeny, Actions: []string{"users:read"}, Conditions: policy.Conditions{
+			{Attribute: "resource.region", Operator: policy.OpEquals, Value: "north"},
+		}},
+		{ID: "concede-rede-interna", Effect: policy.EffectAllow, Actions: []string{"users:write"}, Conditions: policy.Conditions{
+			{Attribute: "context.ip", Operator: policy.OpIPIn, Value: []any{"10.0.0.0/8"}},
+		}},
+	}})
+
+	tests := []struct {
+		name       string
+		permission string
+		resource   policy.Attributes
+		context    policy.Attributes
+		want       models.AuthzDecision
+	}{
+		{"permissão dos papéis sem política aplicável", "users:read", policy.Attributes{"region": "south"}, nil,
+			models.AuthzDecision{Allowed: true, Reason: models.DecisionGlobalPermission}},
+		{"negação prevalece sobre a permissão dos papéis", "users:read", policy.Attributes{"region": "north"}, nil,
+			models.AuthzDecision{Reason: models.DecisionPolicyDenied, PolicyID: "nega-norte"}},
+		{"concessão sem a permissão nos papéis", "users:write", nil, policy.Attributes{"ip": "10.1.2.3"},
+			models.AuthzDecision{Allowed: true, Reason: models.DecisionPolicy, PolicyID: "concede-rede-interna"}},
+		{"sem política nem permissão", "users:write", nil, policy.Attributes{"ip": "203.0.113.7"},
+			models.AuthzDecision{Reason: models.DecisionMissingPermission}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := service.Check(models.AuthzCheckRequest{
+				UserID:         userID,
+				OrganizationID: orgID.String(),
+				Permission:     tt.permission,
+				Resource:       tt.resource,
+				Context:        tt.context,
+			})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if decision != tt.want {
+				t.Errorf("decisão = %+v, esperado %+v", decision, tt.want)
+			}
+		})
+	}
+}
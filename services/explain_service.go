@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/permission"
+	"go-google/repository"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExplainService explica de onde vêm as permissões globais dos usuários e
+// simula o efeito de alterações nos seus grupos. Os papéis e permissões vêm do
+// AccessResolver, o mesmo usado nos tokens; o grafo de acesso apenas descreve
+// os caminhos de concessão.
+type ExplainService struct {
+	userRepo  *repository.UserRepository
+	groupRepo *repository.GroupRepository
+	roleRepo  *repository.RoleRepository
+	access    *AccessResolver
+}
+
+// NewExplainService cria um novo serviço de explicação de permissões
+func NewExplainService(userRepo *repository.UserRepository, groupRepo *repository.GroupRepository, roleRepo *repository.RoleRepository, access *AccessResolver) *ExplainService {
+	return &ExplainService{
+		userRepo:  userRepo,
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+		access:    access,
+	}
+}
+
+// accessGraph reúne os grupos e papéis com suas heranças para percorrer os
+// caminhos de concessão
+type accessGraph struct {
+	groups map[uuid.UUID]models.Group
+	roles  map[uuid.UUID]models.Role
+}
+
+// accessGrants acumula os grupos e as concessões encontrados no percurso
+type accessGrants struct {
+	groups []string
+	grants []models.PermissionGrant
+}
+
+// EffectivePermissions retorna os grupos, papéis e permissões efetivos do membro
+// em toda a organização, com os caminhos de concessão quando query.Explain é verdadeiro. Com
+// AddGroups ou RemoveGroups, o resultado reflete o estado simulado e traz as
+// diferenças em relação ao atual. As atribuições com escopo não são incluídas.
+func (s *ExplainService) EffectivePermissions(orgID uuid.UUID, userID string, query models.EffectivePermissionsQuery) (*models.EffectivePermissions, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.findUser(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := s.loadGraph(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	directGroups, err := s.userRepo.FindGroupIDs(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	currentRoles, currentPermissions, err := s.access.ResolveGroups(orgID, user.Roles, directGroups)
+	if err != nil {
+		return nil, err
+	}
+	groups, roles, permissions := directGroups, currentRoles, currentPermissions
+
+	simulate := len(query.AddGroups) > 0 || len(query.RemoveGroups) > 0
+	if simulate {
+		groups, err = graph.applyGroupChanges(directGroups, query.AddGroups, query.RemoveGroups)
+		if err != nil {
+			return nil, err
+		}
+		roles, permissions, err = s.access.ResolveGroups(orgID, user.Roles, groups)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths := graph.resolve(user.Roles, groups)
+	response := &models.EffectivePermissions{
+		UserID:      user.ID,
+		Groups:      paths.groups,
+		Roles:       roles,
+		Permissions: []models.EffectivePermission{},
+	}
+	for _, perm := range permissions {
+		effective := models.EffectivePermission{Permission: perm}
+		if query.Explain {
+			effective.GrantedBy = paths.grantsFor(perm)
+		}
+		response.Permissions = append(response.Permissions, effective)
+	}
+
+	if simulate {
+		response.Simulation = &models.PermissionSimulation{
+			AddGroups:          nonNil(query.AddGroups),
+			RemoveGroups:       nonNil(query.RemoveGroups),
+			AddedRoles:         difference(roles, currentRoles),
+			RemovedRoles:       difference(currentRoles, roles),
+			AddedPermissions:   uncovered(permissions, currentPermissions),
+			RemovedPermissions: uncovered(currentPermissions, permissions),
+		}
+	}
+	return response, nil
+}
+
+// findUser busca o membro da organização com seus papéis diretos
+func (s *ExplainService) findUser(orgID uuid.UUID, userID string) (*models.User, error) {
+	user, err := s.userRepo.FindMember(orgID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// loadGraph carrega os grupos e papéis da organização com suas heranças
+func (s *ExplainService) loadGraph(orgID uuid.UUID) (*accessGraph, error) {
+	groups, err := s.groupRepo.ListAll(orgID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.ListAll(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &accessGraph{
+		groups: make(map[uuid.UUID]models.Group, len(groups)),
+		roles:  make(map[uuid.UUID]models.Role, len(roles)),
+	}
+	for _, group := range groups {
+		graph.groups[group.ID] = group
+	}
+	for _, role := range roles {
+		graph.roles[role.ID] = role
+	}
+	return graph, nil
+}
+
+// applyGroupChanges aplica as inclusões e remoções simuladas aos grupos diretos
+func (g *accessGraph) applyGroupChanges(direct []uuid.UUID, add, remove []string) ([]uuid.UUID, error) {
+	parse := func(ids []string) ([]uuid.UUID, error) {
+		var parsed []uuid.UUID
+		for _, id := range ids {
+			groupID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, ErrGroupNotFound
+			}
+			if _, ok := g.groups[groupID]; !ok {
+				return nil, ErrGroupNotFound
+			}
+			parsed = append(parsed, groupID)
+		}
+		return parsed, nil
+	}
+
+	added, err := parse(add)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := parse(remove)
+	if err != nil {
+		return nil, err
+	}
+
+	simulated := []uuid.UUID{}
+	for _, id := range direct {
+		if !slices.Contains(removed, id) {
+			simulated = append(simulated, id)
+		}
+	}
+	for _, id := range added {
+		if !slices.Contains(simulated, id) {
+			simulated = append(simulated, id)
+		}
+	}
+	return simulated, nil
+}
+
+// resolve percorre os papéis diretos e os grupos diretos (com os grupos
+// ancestrais) do usuário, registrando os grupos alcançados e cada caminho até
+// uma permissão
+func (g *accessGraph) resolve(directRoles []models.Role, directGroups []uuid.UUID) accessGrants {
+	grants := accessGrants{groups: []string{}, grants: []models.PermissionGrant{}}
+	seenGroups := map[uuid.UUID]bool{}
+
+	var walkRole func(roleID uuid.UUID, path []models.GrantStep)
+	walkRole = func(roleID uuid.UUID, path []models.GrantStep) {
+		role, ok := g.roles[roleID]
+		if !ok || pathContains(path, models.GrantStepRole, roleID) {
+			return
+		}
+
+		path = appendStep(path, models.GrantStep{Type: models.GrantStepRole, ID: role.ID, Name: role.Name})
+		for _, perm := range role.Permissions {
+			grants.grants = append(grants.grants, models.PermissionGrant{Granted: perm, Path: path})
+		}
+		for _, parent := range role.Parents {
+			walkRole(parent.ID, path)
+		}
+	}
+
+	var walkGroup func(groupID uuid.UUID, path []models.GrantStep)
+	walkGroup = func(groupID uuid.UUID, path []models.GrantStep) {
+		group, ok := g.groups[groupID]
+		if !ok || pathContains(path, models.GrantStepGroup, groupID) {
+			return
+		}
+		if !seenGroups[groupID] {
+			seenGroups[groupID] = true
+			grants.groups = append(grants.groups, group.Name)
+		}
+
+		path = appendStep(path, models.GrantStep{Type: models.GrantStepGroup, ID: group.ID, Name: group.Name})
+		for _, role := range group.Roles {
+			walkRole(role.ID, path)
+		}
+		for _, parent := range group.Parents {
+			walkGroup(parent.ID, path)
+		}
+	}
+
+	for _, role := range directRoles {
+		walkRole(role.ID, nil)
+	}
+	for _, groupID := range directGroups {
+		walkGroup(groupID, nil)
+	}
+
+	sort.Strings(grants.groups)
+	return grants
+}
+
+// grantsFor retorna os caminhos cujas permissões cobrem a permissão efetiva
+func (a accessGrants) grantsFor(perm string) []models.PermissionGrant {
+	var grants []models.PermissionGrant
+	for _, grant := range a.grants {
+		if permission.Matches(grant.Granted, perm) {
+			grants = append(grants, grant)
+		}
+	}
+	return grants
+}
+
+// appendStep copia o caminho antes de acrescentar o passo, para que os ramos
+// do percurso não compartilhem o mesmo array
+func appendStep(path []models.GrantStep, step models.GrantStep) []models.GrantStep {
+	extended := make([]models.GrantStep, len(path), len(path)+1)
+	copy(extended, path)
+	return append(extended, step)
+}
+
+// pathContains indica se o grupo ou papel já está no caminho (herança cíclica)
+func pathContains(path []models.GrantStep, stepType string, id uuid.UUID) bool {
+	for _, step := range path {
+		if step.Type == stepType && step.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// difference retorna os itens de a que não estão em b
+func difference(a, b []string) []string {
+	diff := []string{}
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			diff = append(diff, item)
+		}
+	}
+	return diff
+}
+
+// uncovered retorna as permissões de a que não são cobertas pelas de b, para
+// que a troca de users:read por users:* não apareça como perda de users:read
+func uncovered(a, b []string) []string {
+	diff := []string{}
+	for _, perm := range a {
+		if !permission.Allowed(b, perm) {
+			diff = append(diff, perm)
+		}
+	}
+	return diff
+}
+
+// nonNil troca uma lista nula por uma lista vazia na resposta
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// testGraph monta um grafo com herança de papéis, herança de grupos e um ciclo:
+//
+//	viewer (users:read) <- editor (users:write)
+//	staff [viewer] <- support [editor]
+//	ops [superuser (users:*)]
+//	loopA <-> loopB [viewer]
+func testGraph() (*accessGraph, map[string]uuid.UUID) {
+	ids := map[string]uuid.UUID{}
+	for _, name := range []string{"viewer", "editor", "superuser", "staff", "support", "ops", "loopA", "loopB"} {
+		ids[name] = uuid.New()
+	}
+
+	viewer := models.Role{ID: ids["viewer"], Name: "viewer", Permissions: []string{"users:read"}}
+	editor := models.Role{ID: ids["editor"], Name: "editor", Permissions: []string{"users:write"}, Parents: []models.Role{viewer}}
+	superuser := models.Role{ID: ids["superuser"], Name: "superuser", Permissions: []string{"users:*"}}
+
+	staff := models.Group{ID: ids["staff"], Name: "staff", Roles: []models.Role{viewer}}
+	support := models.Group{ID: ids["support"], Name: "support", Roles: []models.Role{editor}, Parents: []models.Group{staff}}
+	ops := models.Group{ID: ids["ops"], Name: "ops", Roles: []models.Role{superuser}}
+	loopA := models.Group{ID: ids["loopA"], Name: "loopA", Parents: []models.Group{{ID: ids["loopB"]}}}
+	loopB := models.Group{ID: ids["loopB"], Name: "loopB", Roles: []models.Role{viewer}, Parents: []models.Group{{ID: ids["loopA"]}}}
+
+	graph := &accessGraph{
+		groups: map[uuid.UUID]models.Group{},
+		roles:  map[uuid.UUID]models.Role{},
+	}
+	for _, group := range []models.Group{staff, support, ops, loopA, loopB} {
+		graph.groups[group.ID] = group
+	}
+	for _, role := range []models.Role{viewer, editor, superuser} {
+		graph.roles[role.ID] = role
+	}
+	return graph, ids
+}
+
+// grantPaths descreve as concessões como "permissão: passo > passo"
+func grantPaths(grants []models.PermissionGrant) []string {
+	var paths []string
+	for _, grant := range grants {
+		var steps []string
+		for _, step := range grant.Path {
+			steps = append(steps, step.Name)
+		}
+		paths = append(paths, grant.Granted+": "+strings.Join(steps, " > "))
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestAccessGraphResolve(t *testing.T) {
+	graph, ids := testGraph()
+
+	tests := []struct {
+		name   string
+		roles  []string
+		groups []string
+		want   accessGrants
+		paths  []string
+	}{
+		{
+			name:  "papel direto com herança",
+			roles: []string{"editor"},
+			paths: []string{"users:read: editor > viewer", "users:write: editor"},
+			want:  accessGrants{groups: []string{}},
+		},
+		{
+			name:   "grupo com grupo ancestral",
+			groups: []string{"support"},
+			paths: []string{
+				"users:read: support > editor > viewer",
+				"users:read: support > staff > viewer",
+				"users:write: support > editor",
+			},
+			want: accessGrants{groups: []string{"staff", "support"}},
+		},
+		{
+			name:   "papel direto e grupo",
+			roles:  []string{"viewer"},
+			groups: []string{"ops"},
+			paths:  []string{"users:*: ops > superuser", "users:read: viewer"},
+			want:   accessGrants{groups: []string{"ops"}},
+		},
+		{
+			name:   "grupos em ciclo",
+			groups: []string{"loopA"},
+			paths:  []string{"users:read: loopA > loopB > viewer"},
+			want:   accessGrants{groups: []string{"loopA", "loopB"}},
+		},
+		{
+			name: "sem papéis nem grupos",
+			want: accessGrants{groups: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var roles []models.Role
+			for _, name := range tt.roles {
+				roles = append(roles, graph.roles[ids[name]])
+			}
+			var groups []uuid.UUID
+			for _, name := range tt.groups {
+				groups = append(groups, ids[name])
+			}
+
+			got := graph.resolve(roles, groups)
+			if !slices.Equal(got.groups, tt.want.groups) {
+				t.Errorf("groups = %v, esperado %v", got.groups, tt.want.groups)
+			}
+			if paths := grantPaths(got.grants); !slices.Equal(paths, tt.paths) {
+				t.Errorf("caminhos = %v, esperado %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestAccessGrantsFor(t *testing.T) {
+	graph, ids := testGraph()
+	grants := graph.resolve(nil, []uuid.UUID{ids["support"], ids["ops"]})
+
+	// users:write implica users:read no verificador padrão
+	want := []string{
+		"users:*: ops > superuser",
+		"users:read: support > editor > viewer",
+		"users:read: support > staff > viewer",
+		"users:write: support > editor",
+	}
+	if got := grantPaths(grants.grantsFor("users:read")); !slices.Equal(got, want) {
+		t.Errorf("grantsFor(users:read) = %v, esperado %v", got, want)
+	}
+}
+
+func TestApplyGroupChanges(t *testing.T) {
+	graph, ids := testGraph()
+
+	tests := []struct {
+		name    string
+		direct  []string
+		add     []string
+		remove  []string
+		want    []string
+		wantErr error
+	}{
+		{name: "inclusão", direct: []string{"staff"}, add: []string{"ops"}, want: []string{"staff", "ops"}},
+		{name: "remoção", direct: []string{"staff", "ops"}, remove: []string{"staff"}, want: []string{"ops"}},
+		{name: "inclusão de grupo já direto", direct: []string{"staff"}, add: []string{"staff"}, want: []string{"staff"}},
+		{name: "troca de grupo", direct: []string{"staff"}, add: []string{"support"}, remove: []string{"staff"}, want: []string{"support"}},
+		{name: "remoção de grupo não direto", direct: []string{"staff"}, remove: []string{"ops"}, want: []string{"staff"}},
+		{name: "remoção de todos", direct: []string{"staff"}, remove: []string{"staff"}, want: []string{}},
+		{name: "ID inválido", direct: []string{"staff"}, add: []string{"não-é-uuid"}, wantErr: ErrGroupNotFound},
+		{name: "grupo de outra organização", direct: []string{"staff"}, remove: []string{uuid.NewString()}, wantErr: ErrGroupNotFound},
+	}
+
+	resolveIDs := func(names []string) []string {
+		var resolved []string
+		for _, name := range names {
+			if id, ok := ids[name]; ok {
+				resolved = append(resolved, id.String())
+			} else {
+				resolved = append(resolved, name)
+			}
+		}
+		return resolved
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var direct []uuid.UUID
+			for _, name := range tt.direct {
+				direct = append(direct, ids[name])
+			}
+
+			got, err := graph.applyGroupChanges(direct, resolveIDs(tt.add), resolveIDs(tt.remove))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("erro = %v, esperado %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyGroupChanges: %v", err)
+			}
+
+			var want []uuid.UUID
+			for _, name := range tt.want {
+				want = append(want, ids[name])
+			}
+			if len(got) != len(want) || (len(want) > 0 && !slices.Equal(got, want)) {
+				t.Errorf("grupos = %v, esperado %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUncovered(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []string
+	}{
+		{"coberta por curinga", []string{"users:read"}, []string{"users:*"}, []string{}},
+		{"curinga não coberto", []string{"users:*"}, []string{"users:read"}, []string{"users:*"}},
+		{"permissão perdida", []string{"users:read", "groups:read"}, []string{"users:read"}, []string{"groups:read"}},
+		{"sem permissões", []string{"users:read"}, nil, []string{"users:read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uncovered(tt.a, tt.b); !slices.Equal(got, tt.want) {
+				t.Errorf("uncovered(%v, %v) = %v, esperado %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-google/models"
+	"go-google/repository"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrGroupNotFound indica que o grupo informado não existe
+	ErrGroupNotFound = errors.New("grupo não encontrado")
+	// ErrGroupExists indica que já existe um grupo com o nome informado
+	ErrGroupExists = errors.New("já existe um grupo com este nome")
+	// ErrParentGroupNotFound indica que um dos grupos pais informados não existe
+	ErrParentGroupNotFound = errors.New("grupo pai não encontrado")
+	// ErrGroupCycle indica que os grupos pais informados formariam um ciclo
+	ErrGroupCycle = errors.New("um grupo não pode conter a si mesmo, direta ou indiretamente")
+	// ErrUserNotFound indica que o usuário informado não existe
+	ErrUserNotFound = errors.New("usuário não encontrado")
+	// ErrDelegationExceeded impede que uma permissão delegada sobre o grupo
+	// conceda papéis que o próprio solicitante não tem
+	ErrDelegationExceeded = errors.New("o grupo concede papéis que você não possui")
+)
+
+// GroupService manipula a lógica de negócio relacionada a grupos e seus membros
+type GroupService struct {
+	groupRepo *repository.GroupRepository
+	roleRepo  *repository.RoleRepository
+	userRepo  *repository.UserRepository
+	authz     *AuthzService
+	access    *AccessResolver
+}
+
+// NewGroupService cria um novo serviço de grupos
+func NewGroupService(groupRepo *repository.GroupRepository, roleRepo *repository.RoleRepository, userRepo *repository.UserRepository, authz *AuthzService, access *AccessResolver) *GroupService {
+	return &GroupService{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+		userRepo:  userRepo,
+		authz:     authz,
+		access:    access,
+	}
+}
+
+// ListGroups lista os grupos da organização com seus papéis
+func (s *GroupService) ListGroups(orgID uuid.UUID) ([]models.Group, error) {
+	return s.groupRepo.ListAll(orgID)
+}
+
+// GetGroup busca um grupo da organização pelo ID
+func (s *GroupService) GetGroup(orgID uuid.UUID, id string) (*models.Group, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrGroupNotFound
+	}
+
+	group, err := s.groupRepo.FindByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
+// CreateGroup cria um novo grupo na organização
+func (s *GroupService) CreateGroup(orgID uuid.UUID, req models.GroupRequest) (*models.Group, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkNameAvailable(orgID, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.findRoles(orgID, req.RoleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Um grupo novo não tem subgrupos, então seus pais não podem formar ciclo
+	parents, err := s.findParents(orgID, req.ParentIDs, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.Group{
+		OrganizationID: orgID,
+		Name:           name,
+		Description:    req.Description,
+		Roles:          roles,
+		Parents:        parents,
+	}
+	if err := s.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UpdateGroup atualiza o nome e a descrição de um grupo e, quando role_ids ou
+// parent_ids são informados, substitui também os seus papéis ou grupos pais
+func (s *GroupService) UpdateGroup(orgID uuid.UUID, id string, req models.GroupRequest) (*models.Group, error) {
+	group, err := s.GetGroup(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkNameAvailable(orgID, name, group.ID); err != nil {
+		return nil, err
+	}
+
+	var parents []models.Group
+	if req.ParentIDs != nil {
+		if parents, err = s.findParents(orgID, req.ParentIDs, group.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	group.Name = name
+	group.Description = req.Description
+	if err := s.groupRepo.Update(group); err != nil {
+		return nil, err
+	}
+
+	if req.ParentIDs != nil {
+		if err := s.groupRepo.SetParents(group, parents); err != nil {
+			return nil, err
+		}
+		s.authz.InvalidateAll()
+	}
+
+	if req.RoleIDs != nil {
+		return s.AssignRoles(orgID, id, req.RoleIDs)
+	}
+	return s.GetGroup(orgID, id)
+}
+
+// DeleteGroup exclui um grupo, removendo seus membros e papéis
+func (s *GroupService) DeleteGroup(orgID uuid.UUID, id string) error {
+	group, err := s.GetGroup(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(group); err != nil {
+		return err
+	}
+
+	s.authz.InvalidateAll()
+	return nil
+}
+
+// AssignRoles substitui os papéis do grupo
+func (s *GroupService) AssignRoles(orgID uuid.UUID, id string, roleIDs []string) (*models.Group, error) {
+	group, err := s.GetGroup(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.findRoles(orgID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.AssignRoles(group, roles); err != nil {
+		return nil, err
+	}
+
+	s.authz.InvalidateAll()
+	return s.GetGroup(orgID, id)
+}
+
+// ListMembers lista os membros do grupo
+func (s *GroupService) ListMembers(orgID uuid.UUID, id string) ([]models.UserResponse, error) {
+	group, err := s.GetGroup(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.groupRepo.ListMembers(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []models.UserResponse{}
+	for _, user := range users {
+		members = append(members, models.UserResponse{
+			ID:      user.ID,
+			Email:   user.Email,
+			Name:    user.Name,
+			Picture: user.Picture,
+		})
+	}
+	return members, nil
+}
+
+// AddMember adiciona um membro da organização ao grupo, preservando suas outras associações
+func (s *GroupService) AddMember(orgID uuid.UUID, id, userID string) error {
+	group, user, err := s.findMembership(orgID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.AddMember(group.ID, user.ID); err != nil {
+		return err
+	}
+
+	s.authz.Invalidate(user.ID.String())
+	return nil
+}
+
+// RemoveMember remove um usuário do grupo
+func (s *GroupService) RemoveMember(orgID uuid.UUID, id, userID string) error {
+	group, user, err := s.findMembership(orgID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveMember(group.ID, user.ID); err != nil {
+		return err
+	}
+
+	s.authz.Invalidate(user.ID.String())
+	return nil
+}
+
+// DelegatedAddMember adiciona um usuário ao grupo a pedido de quem gerencia o
+// grupo por uma permissão delegada, desde que o solicitante já tenha todos os
+// papéis que o grupo concede
+func (s *GroupService) DelegatedAddMember(orgID uuid.UUID, callerID, id, userID string) error {
+	if err := s.checkDelegation(orgID, callerID, id); err != nil {
+		return err
+	}
+	return s.AddMember(orgID, id, userID)
+}
+
+// DelegatedRemoveMember remove um usuário do grupo a pedido de quem gerencia o
+// grupo por uma permissão delegada, com a mesma restrição de DelegatedAddMember
+func (s *GroupService) DelegatedRemoveMember(orgID uuid.UUID, callerID, id, userID string) error {
+	if err := s.checkDelegation(orgID, callerID, id); err != nil {
+		return err
+	}
+	return s.RemoveMember(orgID, id, userID)
+}
+
+// checkDelegation garante que o solicitante tenha, na organização, todos os
+// papéis concedidos pelo grupo e pelos grupos que o contêm. Sem isso, quem pode
+// gerenciar os membros do grupo poderia entrar nele e herdar seus papéis.
+// Administradores da organização não são restringidos.
+func (s *GroupService) checkDelegation(orgID uuid.UUID, callerID, id string) error {
+	group, err := s.GetGroup(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	granted, _, err := s.access.ResolveGroups(orgID, nil, []uuid.UUID{group.ID})
+	if err != nil {
+		return err
+	}
+	if len(granted) == 0 {
+		return nil
+	}
+
+	caller, err := s.userRepo.FindMember(orgID, callerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDelegationExceeded
+		}
+		return err
+	}
+	held, _, err := s.access.Resolve(orgID, caller)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(held, models.RoleAdmin) {
+		return nil
+	}
+
+	for _, role := range granted {
+		if !slices.Contains(held, role) {
+			return fmt.Errorf("%w: %s", ErrDelegationExceeded, role)
+		}
+	}
+	return nil
+}
+
+// findMembership busca o grupo e o usuário de uma operação de associação,
+// ambos da organização
+func (s *GroupService) findMembership(orgID uuid.UUID, id, userID string) (*models.Group, *models.User, error) {
+	group, err := s.GetGroup(orgID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+	user, err := s.userRepo.FindMember(orgID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrUserNotFound
+		}
+		return nil, nil, err
+	}
+	return group, user, nil
+}
+
+// checkNameAvailable verifica se nenhum outro grupo da organização usa o nome informado
+func (s *GroupService) checkNameAvailable(orgID uuid.UUID, name string, groupID uuid.UUID) error {
+	existing, err := s.groupRepo.FindByName(orgID, name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != groupID {
+		return ErrGroupExists
+	}
+	return nil
+}
+
+// findParents busca os grupos pais informados na organização e rejeita o
+// aninhamento que formaria um ciclo, ou seja, quando o grupo já contém algum dos pais
+func (s *GroupService) findParents(orgID uuid.UUID, parentIDs []string, groupID uuid.UUID) ([]models.Group, error) {
+	var ids []uuid.UUID
+	for _, id := range parentIDs {
+		parentID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, ErrParentGroupNotFound
+		}
+		if parentID == groupID {
+			return nil, ErrGroupCycle
+		}
+		ids = append(ids, parentID)
+	}
+
+	lineage, err := s.groupRepo.FindWithAncestors(orgID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uuid.UUID]models.Group, len(lineage))
+	for _, group := range lineage {
+		if group.ID == groupID {
+			return nil, ErrGroupCycle
+		}
+		found[group.ID] = group
+	}
+
+	parents := []models.Group{}
+	for _, id := range ids {
+		parent, ok := found[id]
+		if !ok {
+			return nil, ErrParentGroupNotFound
+		}
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+// findRoles busca os papéis informados na organização, rejeitando IDs inválidos ou inexistentes
+func (s *GroupService) findRoles(orgID uuid.UUID, roleIDs []string) ([]models.Role, error) {
+	roles := []models.Role{}
+	for _, id := range roleIDs {
+		roleID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, ErrRoleNotFound
+		}
+		role, err := s.roleRepo.FindByID(orgID, roleID)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return nil, ErrRoleNotFound
+		}
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go-google/config"
+	"go-google/mailer"
+	"go-google/models"
+	"go-google/providers"
+	"go-google/repository"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultInvitationTTL define a validade de um convite quando expires_in_hours não é informado
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrInvitationNotFound indica que o convite não existe na organização
+	ErrInvitationNotFound = errors.New("convite não encontrado")
+	// ErrInvitationExists indica que já há um convite pendente para o e-mail
+	ErrInvitationExists = errors.New("já existe um convite pendente para este e-mail")
+	// ErrInvitationNotPending indica que o convite já foi aceito, revogado ou expirou
+	ErrInvitationNotPending = errors.New("o convite não está mais pendente")
+	// ErrInvalidInvitation indica que o link de convite é inválido
+	ErrInvalidInvitation = errors.New("convite inválido")
+	// ErrInvitationEmailMismatch indica que o login foi feito com um e-mail diferente do convidado
+	ErrInvitationEmailMismatch = errors.New("o convite foi enviado para outro e-mail")
+)
+
+// InvitationService manipula os convites para as organizações: emissão do link
+// assinado, envio por e-mail e aceite no login
+type InvitationService struct {
+	config         *config.Config
+	invitationRepo *repository.InvitationRepository
+	groupRepo      *repository.GroupRepository
+	userRepo       *repository.UserRepository
+	organizations  *OrganizationService
+	sender         mailer.Sender
+	authz          *AuthzService
+}
+
+// NewInvitationService cria um novo serviço de convites
+func NewInvitationService(config *config.Config, invitationRepo *repository.InvitationRepository, groupRepo *repository.GroupRepository, userRepo *repository.UserRepository, organizations *OrganizationService, sender mailer.Sender, authz *AuthzService) *InvitationService {
+	return &InvitationService{
+		config:         config,
+		invitationRepo: invitationRepo,
+		groupRepo:      groupRepo,
+		userRepo:       userRepo,
+		organizations:  organizations,
+		sender:         sender,
+		authz:          authz,
+	}
+}
+
+// ListInvitations lista os convites da organização com sua situação
+func (s *InvitationService) ListInvitations(orgID uuid.UUID) ([]models.InvitationResponse, error) {
+	invitations, err := s.invitationRepo.List(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := []models.InvitationResponse{}
+	for i := range invitations {
+		responses = append(responses, invitationResponse(&invitations[i], now))
+	}
+	return responses, nil
+}
+
+// CreateInvitation convida um e-mail para a organização com os papéis e grupos
+// informados e envia o link do convite. Falhas no envio não impedem a criação:
+// o link é retornado para ser repassado ao convidado.
+func (s *InvitationService) CreateInvitation(orgID uuid.UUID, inviterID string, req models.InvitationRequest) (*models.InvitationResponse, error) {
+	invitedBy, err := uuid.Parse(inviterID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := s.checkNotMember(orgID, email); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.invitationRepo.FindPendingByEmail(orgID, email)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, ErrInvitationExists
+	}
+
+	roles, err := s.organizations.memberRoles(orgID, req.RoleIDs)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.findGroups(orgID, req.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.issueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.Invitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Roles:          roles,
+		Groups:         groups,
+		InvitedByID:    invitedBy,
+		TokenHash:      hashToken(token),
+		ExpiresAt:      time.Now().Add(invitationTTL(req.ExpiresInHours)),
+		ExpiresInHours: req.ExpiresInHours,
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	return s.deliver(invitation, token)
+}
+
+// ResendInvitation emite um novo link para um convite não aceito nem revogado,
+// renovando sua validade, e o envia novamente. O link anterior deixa de valer.
+func (s *InvitationService) ResendInvitation(orgID uuid.UUID, id string) (*models.InvitationResponse, error) {
+	invitation, err := s.findInvitation(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.issueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// A validade renovada é a pedida na criação, contada a partir do reenvio
+	expiresAt := time.Now().Add(invitationTTL(invitation.ExpiresInHours))
+	reissued, err := s.invitationRepo.Reissue(invitation, hashToken(token), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !reissued {
+		return nil, ErrInvitationNotPending
+	}
+
+	return s.deliver(invitation, token)
+}
+
+// RevokeInvitation revoga um convite ainda não aceito, invalidando seu link
+func (s *InvitationService) RevokeInvitation(orgID uuid.UUID, id string) error {
+	invitation, err := s.findInvitation(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.invitationRepo.Revoke(invitation)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationNotPending
+	}
+	return nil
+}
+
+// Resolve valida o token de um link de convite e retorna o convite pendente
+func (s *InvitationService) Resolve(token string) (*models.Invitation, error) {
+	encodedNonce, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signToken(encodedNonce))) {
+		return nil, ErrInvalidInvitation
+	}
+
+	invitation, err := s.invitationRepo.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvalidInvitation
+	}
+	if invitation.Status(time.Now()) != models.InvitationPending {
+		return nil, ErrInvitationNotPending
+	}
+	return invitation, nil
+}
+
+// ForLogin carrega o convite vinculado a um login em andamento, exigindo que a
+// identidade autenticada tenha comprovado o e-mail convidado
+func (s *InvitationService) ForLogin(id uuid.UUID, identity *providers.Identity) (*models.Invitation, error) {
+	invitation, err := s.invitationRepo.FindForLogin(id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvalidInvitation
+	}
+	if invitation.Status(time.Now()) != models.InvitationPending {
+		return nil, ErrInvitationNotPending
+	}
+	if !identity.EmailVerified || !strings.EqualFold(identity.Email, invitation.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+	return invitation, nil
+}
+
+// Accept torna o usuário membro da organização do convite com os papéis e
+// grupos pré-atribuídos
+func (s *InvitationService) Accept(invitation *models.Invitation, userID uuid.UUID) error {
+	accepted, err := s.invitationRepo.Accept(invitation, userID)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return ErrInvitationNotPending
+	}
+
+	s.authz.Invalidate(userID.String())
+	return nil
+}
+
+// checkNotMember rejeita convites para quem já participa da organização
+func (s *InvitationService) checkNotMember(orgID uuid.UUID, email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil || user == nil {
+		return err
+	}
+
+	member, err := s.organizations.IsMember(orgID, user.ID)
+	if err != nil {
+		return err
+	}
+	if member {
+		return ErrMemberExists
+	}
+	return nil
+}
+
+// findInvitation busca um convite da organização pelo ID
+func (s *InvitationService) findInvitation(orgID uuid.UUID, id string) (*models.Invitation, error) {
+	invitationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	invitation, err := s.invitationRepo.FindByID(orgID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// findGroups busca os grupos informados na organização, rejeitando IDs inválidos ou inexistentes
+func (s *InvitationService) findGroups(orgID uuid.UUID, groupIDs []string) ([]models.Group, error) {
+	groups := []models.Group{}
+	for _, id := range groupIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, ErrGroupNotFound
+		}
+		group, err := s.groupRepo.FindByID(orgID, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrGroupNotFound
+			}
+			return nil, err
+		}
+		groups = append(groups, *group)
+	}
+	return groups, nil
+}
+
+// deliver envia o link do convite por e-mail e monta a resposta com o link e o
+// resultado do envio
+func (s *InvitationService) deliver(invitation *models.Invitation, token string) (*models.InvitationResponse, error) {
+	organization, err := s.organizations.GetOrganization(invitation.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	link := s.inviteURL(token)
+	sent := true
+	err = s.sender.Send(mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("Convite para %s", organization.Name),
+		Body: fmt.Sprintf("Você foi convidado para participar de %s.\n\n"+
+			"Para aceitar, acesse o link abaixo e entre com a conta do e-mail %s:\n\n%s\n\n"+
+			"O convite expira em %s.\n",
+			organization.Name, invitation.Email, link, invitation.ExpiresAt.Format("02/01/2006 15:04 MST")),
+	})
+	if err != nil {
+		log.Printf("Erro ao enviar o convite %s para %s: %v", invitation.ID, invitation.Email, err)
+		sent = false
+	} else if err := s.invitationRepo.MarkSent(invitation); err != nil {
+		return nil, err
+	}
+
+	response := invitationResponse(invitation, time.Now())
+	response.InviteURL = link
+	response.EmailSent = &sent
+	return &response, nil
+}
+
+// issueToken gera um novo token assinado para o link do convite
+func (s *InvitationService) issueToken() (string, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("erro ao gerar convite: %w", err)
+	}
+
+	encodedNonce := base64.RawURLEncoding.EncodeToString(nonce)
+	return encodedNonce + "." + s.signToken(encodedNonce), nil
+}
+
+// signToken calcula a assinatura HMAC do nonce do convite
+func (s *InvitationService) signToken(encodedNonce string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTSecret))
+	mac.Write([]byte("invitation:" + encodedNonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// inviteURL acrescenta o token do convite à FRONTEND_URL
+func (s *InvitationService) inviteURL(token string) string {
+	params := url.Values{"invitation": {token}}
+	if strings.Contains(s.config.FrontendURL, "?") {
+		return s.config.FrontendURL + "&" + params.Encode()
+	}
+	return s.config.FrontendURL + "?" + params.Encode()
+}
+
+// invitationTTL retorna a validade de um convite pelas horas pedidas, ou
+// DefaultInvitationTTL quando não informadas
+func invitationTTL(hours int) time.Duration {
+	if hours <= 0 {
+		return DefaultInvitationTTL
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// invitationResponse monta a visão do convite com sua situação no instante informado
+func invitationResponse(invitation *models.Invitation, now time.Time) models.InvitationResponse {
+	roles := []string{}
+	for _, role := range invitation.Roles {
+		roles = append(roles, role.Name)
+	}
+	groups := []string{}
+	for _, group := range invitation.Groups {
+		groups = append(groups, group.Name)
+	}
+
+	return models.InvitationResponse{
+		ID:         invitation.ID,
+		Email:      invitation.Email,
+		Status:     invitation.Status(now),
+		Roles:      roles,
+		Groups:     groups,
+		ExpiresAt:  invitation.ExpiresAt,
+		SentAt:     invitation.SentAt,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt:  invitation.RevokedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-google/config"
+	"go-google/keys"
+	"go-google/models"
+	"go-google/oidc"
+	"go-google/repository"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// keyRingCheckInterval define de quanto em quanto tempo o key ring é
+	// recarregado do banco e a rotação agendada é verificada
+	keyRingCheckInterval = time.Minute
+	// keyRingMinReload limita recargas disparadas por kid desconhecido
+	keyRingMinReload = 10 * time.Second
+)
+
+var (
+	// ErrSigningKeyNotFound indica que a chave informada não existe
+	ErrSigningKeyNotFound = errors.New("chave de assinatura não encontrada")
+	// ErrStaticSigningKey indica que a chave vem de arquivo e não pode ser rotacionada pela API
+	ErrStaticSigningKey = errors.New("a chave de assinatura é carregada de arquivo (JWT_PRIVATE_KEY_FILE) e não pode ser rotacionada pela API")
+)
+
+// ringKey é uma chave do key ring junto com seus metadados
+type ringKey struct {
+	key    *keys.Key
+	record models.SigningKey
+}
+
+// KeyService mantém o key ring: uma chave de assinatura e as chaves que ainda
+// verificam tokens, incluindo as substituídas dentro do período de carência
+type KeyService struct {
+	config *config.Config
+	repo   *repository.SigningKeyRepository
+
+	// rotateMu serializa as rotações desta instância
+	rotateMu sync.Mutex
+
+	mu       sync.RWMutex
+	signing  *ringKey
+	keys     map[string]*ringKey
+	loadedAt time.Time
+	// skipped guarda as chaves que nenhum segredo decifra, para registrá-las uma única vez
+	skipped map[string]bool
+}
+
+// NewKeyService cria um novo serviço de chaves de assinatura
+func NewKeyService(config *config.Config, repo *repository.SigningKeyRepository) *KeyService {
+	return &KeyService{
+		config:  config,
+		repo:    repo,
+		keys:    make(map[string]*ringKey),
+		skipped: make(map[string]bool),
+	}
+}
+
+// Load carrega a chave de assinatura do arquivo PEM configurado ou, na falta
+// dele, o key ring armazenado no banco, gerando uma chave quando não houver nenhuma ativa
+func (s *KeyService) Load() error {
+	if s.static() {
+		return s.loadFromFile()
+	}
+
+	if s.config.JWTKeyGracePeriod < RefreshTokenTTL {
+		log.Printf("Aviso: JWT_KEY_GRACE_PERIOD (%s) é menor que a validade dos tokens de atualização (%s); rotações encerrarão sessões", s.config.JWTKeyGracePeriod, RefreshTokenTTL)
+	}
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	signing := s.signing
+	s.mu.RUnlock()
+	if signing == nil {
+		s.rotateMu.Lock()
+		defer s.rotateMu.Unlock()
+		_, err := s.rotate(false)
+		return err
+	}
+	return nil
+}
+
+// Start inicia a manutenção periódica do key ring: recarga das chaves criadas
+// por outras instâncias, rotação agendada e aposentadoria das chaves expiradas
+func (s *KeyService) Start() {
+	if s.static() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(keyRingCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.maintain(); err != nil {
+				log.Printf("Erro na manutenção das chaves de assinatura: %v", err)
+			}
+		}
+	}()
+}
+
+// static indica se a chave de assinatura vem de um arquivo PEM
+func (s *KeyService) static() bool {
+	return s.config.JWTPrivateKeyFile != ""
+}
+
+// loadFromFile carrega a chave privada do arquivo PEM configurado
+func (s *KeyService) loadFromFile() error {
+	data, err := os.ReadFile(s.config.JWTPrivateKeyFile)
+	if err != nil {
+		return fmt.Errorf("erro ao ler chave de assinatura: %w", err)
+	}
+
+	key, err := keys.ParsePrivateKeyPEM(data, s.config.JWTKeyID, s.config.JWTSigningAlg)
+	if err != nil {
+		return err
+	}
+
+	entry := &ringKey{
+		key:    key,
+		record: models.SigningKey{ID: key.ID, Algorithm: key.Algorithm, Status: models.SigningKeyActive},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signing = entry
+	s.keys = map[string]*ringKey{key.ID: entry}
+	s.loadedAt = time.Now()
+	return nil
+}
+
+// reload carrega do banco as chaves que ainda verificam tokens. A chave ativa
+// mais recente com o algoritmo configurado assina. Chaves que não podem ser
+// decifradas (segredo de cifragem trocado sem informar o anterior) são
+// ignoradas; sem chave de assinatura, uma nova é gerada.
+func (s *KeyService) reload() error {
+	now := time.Now()
+	stored, err := s.repo.ListUsable(now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	previous := s.keys
+	s.mu.RUnlock()
+
+	loaded := make(map[string]*ringKey, len(stored))
+	skipped := make(map[string]bool)
+	var signing *ringKey
+	for _, record := range stored {
+		entry, err := s.openKey(record, previous[record.ID])
+		if errors.Is(err, keys.ErrUndecryptable) {
+			if !s.wasSkipped(record.ID) {
+				log.Printf("Chave de assinatura ignorada: kid=%s: %v", record.ID, err)
+			}
+			skipped[record.ID] = true
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao carregar chave de assinatura %s: %w", record.ID, err)
+		}
+		loaded[record.ID] = entry
+		if signing == nil && record.Status == models.SigningKeyActive && record.Algorithm == s.config.JWTSigningAlg {
+			signing = entry
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signing = signing
+	s.keys = loaded
+	s.skipped = skipped
+	s.loadedAt = now
+	return nil
+}
+
+// wasSkipped indica se a chave já foi ignorada na recarga anterior
+func (s *KeyService) wasSkipped(kid string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.skipped[kid]
+}
+
+// openKey decifra a chave privada armazenada, reaproveitando a já carregada
+func (s *KeyService) openKey(record models.SigningKey, cached *ringKey) (*ringKey, error) {
+	if cached != nil {
+		return &ringKey{key: cached.key, record: record}, nil
+	}
+
+	secrets := append([]string{s.config.JWTKeyEncryptionSecret}, s.config.JWTKeyEncryptionPrevious...)
+	privatePEM, err := keys.Open(record.PrivateKey, secrets...)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keys.ParsePrivateKeyPEM(privatePEM, record.ID, record.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &ringKey{key: key, record: record}, nil
+}
+
+// maintain recarrega o key ring, rotaciona a chave de assinatura quando o
+// intervalo configurado venceu e aposenta as chaves fora da carência
+func (s *KeyService) maintain() error {
+	now := time.Now()
+	if err := s.repo.RetireExpired(now); err != nil {
+		return err
+	}
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	interval := s.config.JWTKeyRotationInterval
+	s.mu.RLock()
+	signing := s.signing
+	s.mu.RUnlock()
+	if signing != nil && (interval <= 0 || now.Sub(signing.record.CreatedAt) < interval) {
+		return nil
+	}
+
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+
+	if signing == nil {
+		// O algoritmo configurado mudou ou a chave ativa foi aposentada em outra instância
+		_, err := s.rotate(false)
+		return err
+	}
+
+	// Apenas a instância que conseguir rebaixar a chave cria a próxima
+	demoted, err := s.repo.Demote(signing.record.ID, now.Add(s.config.JWTKeyGracePeriod))
+	if err != nil {
+		return err
+	}
+	if !demoted {
+		return s.reload()
+	}
+
+	record, err := s.createKey()
+	if err != nil {
+		return err
+	}
+	log.Printf("Chave de assinatura rotacionada: kid=%s alg=%s", record.ID, record.Algorithm)
+	return s.reload()
+}
+
+// Rotate gera uma nova chave de assinatura imediatamente. As chaves ativas
+// anteriores continuam verificando tokens durante o período de carência, ou são
+// aposentadas na hora quando retirePrevious é verdadeiro (chave comprometida).
+func (s *KeyService) Rotate(retirePrevious bool) (*models.SigningKey, error) {
+	if s.static() {
+		return nil, ErrStaticSigningKey
+	}
+
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+	return s.rotate(retirePrevious)
+}
+
+// rotate substitui as chaves ativas por uma nova (o chamador detém rotateMu)
+func (s *KeyService) rotate(retirePrevious bool) (*models.SigningKey, error) {
+	active, err := s.repo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	verifyUntil := time.Now().Add(s.config.JWTKeyGracePeriod)
+	for _, key := range active {
+		if retirePrevious {
+			err = s.repo.Retire(key.ID)
+		} else {
+			_, err = s.repo.Demote(key.ID, verifyUntil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := s.createKey()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Nova chave de assinatura gerada: kid=%s alg=%s", record.ID, record.Algorithm)
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Retire aposenta a chave imediatamente. Se for a chave de assinatura, uma
+// nova é gerada antes, sem período de carência para a antiga.
+func (s *KeyService) Retire(kid string) error {
+	if s.static() {
+		return ErrStaticSigningKey
+	}
+
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+
+	record, err := s.repo.FindByID(kid)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return ErrSigningKeyNotFound
+	}
+
+	if record.Status == models.SigningKeyActive {
+		_, err := s.rotate(true)
+		return err
+	}
+	if err := s.repo.Retire(kid); err != nil {
+		return err
+	}
+	log.Printf("Chave de assinatura aposentada: kid=%s", kid)
+	return s.reload()
+}
+
+// ListKeys lista os metadados de todas as chaves, sem o material privado
+func (s *KeyService) ListKeys() ([]models.SigningKey, error) {
+	if s.static() {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return []models.SigningKey{s.signing.record}, nil
+	}
+	return s.repo.ListAll()
+}
+
+// createKey gera uma chave com o algoritmo configurado e a armazena cifrada
+// com o segredo de cifragem atual
+func (s *KeyService) createKey() (*models.SigningKey, error) {
+	key, err := keys.Generate(s.config.JWTSigningAlg)
+	if err != nil {
+		return nil, err
+	}
+
+	privatePEM, err := keys.MarshalPrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := keys.Seal(privatePEM, s.config.JWTKeyEncryptionSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.SigningKey{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: sealed,
+		Status:     models.SigningKeyActive,
+	}
+	if err := s.repo.Create(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Sign assina as claims com a chave atual, informando o kid no cabeçalho
+func (s *KeyService) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	signing := s.signing
+	s.mu.RUnlock()
+	if signing == nil {
+		return "", errors.New("nenhuma chave de assinatura carregada")
+	}
+
+	token := jwt.NewWithClaims(signing.key.SigningMethod(), claims)
+	token.Header["kid"] = signing.key.ID
+	return token.SignedString(signing.key.Private)
+}
+
+// Keyfunc localiza a chave pública pelo kid do token, exigindo que o algoritmo
+// do cabeçalho corresponda ao da chave. Um kid desconhecido força a recarga do
+// key ring, pois a chave pode ter sido criada por outra instância.
+func (s *KeyService) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	entry, ok := s.lookup(kid)
+	if !ok && !s.static() && s.canReload() {
+		if err := s.reload(); err != nil {
+			log.Printf("Erro ao recarregar chaves de assinatura: %v", err)
+		}
+		entry, ok = s.lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("chave de assinatura desconhecida: %s", kid)
+	}
+
+	if token.Method.Alg() != entry.key.Algorithm {
+		return nil, errors.New("método de assinatura inválido")
+	}
+	if entry.record.VerifyUntil != nil && time.Now().After(*entry.record.VerifyUntil) {
+		return nil, errors.New("chave de assinatura expirada")
+	}
+	return entry.key.Public(), nil
+}
+
+// lookup busca a chave pelo kid no key ring em memória
+func (s *KeyService) lookup(kid string) (*ringKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.keys[kid]
+	return entry, ok
+}
+
+// canReload limita a frequência das recargas sob demanda
+func (s *KeyService) canReload() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return time.Since(s.loadedAt) >= keyRingMinReload
+}
+
+// JWKS retorna as chaves públicas de verificação no formato JWKS
+func (s *KeyService) JWKS() oidc.JSONWebKeySet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keySet := oidc.JSONWebKeySet{Keys: make([]oidc.JSONWebKey, 0, len(s.keys))}
+	for _, entry := range s.keys {
+		keySet.Keys = append(keySet.Keys, entry.key.JWK())
+	}
+	return keySet
+}
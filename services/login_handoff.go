@@ -0,0 +1,126 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-google/keys"
+	"go-google/models"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HandoffCodeTTL define por quanto tempo o código entregue ao frontend pode ser trocado
+const HandoffCodeTTL = time.Minute
+
+// ErrInvalidHandoffCode indica um código de troca inválido, expirado ou já utilizado
+var ErrInvalidHandoffCode = errors.New("código de troca inválido ou expirado")
+
+// GetFrontendRedirectURL emite um código opaco de uso único para o resultado do
+// login e monta a URL de redirecionamento para o frontend. Os tokens nunca vão
+// na URL: o frontend os obtém trocando o código em POST /auth/exchange.
+func (s *AuthService) GetFrontendRedirectURL(userWithToken *models.UserWithToken, returnTo string) (string, error) {
+	code, err := s.issueHandoffCode(userWithToken)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Add("code", code)
+	return s.frontendURL(params, returnTo), nil
+}
+
+// GetFrontendSessionURL monta a URL de redirecionamento para o frontend no modo
+// de sessão por cookies, em que os tokens já foram gravados em cookies
+func (s *AuthService) GetFrontendSessionURL(returnTo string) string {
+	return s.frontendURL(url.Values{}, returnTo)
+}
+
+// frontendURL acrescenta os parâmetros e o caminho de retorno à FRONTEND_URL
+func (s *AuthService) frontendURL(params url.Values, returnTo string) string {
+	baseURL := s.config.FrontendURL
+	if returnTo != "" {
+		params.Add("return_to", returnTo)
+	}
+	if len(params) == 0 {
+		return baseURL
+	}
+
+	if strings.Contains(baseURL, "?") {
+		return baseURL + "&" + params.Encode()
+	}
+	return baseURL + "?" + params.Encode()
+}
+
+// issueHandoffCode armazena o resultado do login cifrado e retorna o código de troca
+func (s *AuthService) issueHandoffCode(userWithToken *models.UserWithToken) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("erro ao gerar código de troca: %w", err)
+	}
+	code := base64.RawURLEncoding.EncodeToString(raw)
+
+	payload, err := json.Marshal(userWithToken)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := keys.Seal(payload, s.config.JWTSecret)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.handoffRepo.Create(&models.LoginHandoff{
+		CodeHash:  hashToken(code),
+		Payload:   sealed,
+		ExpiresAt: now.Add(HandoffCodeTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao registrar código de troca: %w", err)
+	}
+
+	// Limpeza oportunista dos códigos que nunca foram trocados
+	if err := s.handoffRepo.DeleteExpired(now); err != nil {
+		log.Printf("Erro ao remover códigos de troca expirados: %v", err)
+	}
+
+	return code, nil
+}
+
+// ExchangeHandoffCode troca o código de uso único pelo resultado do login
+func (s *AuthService) ExchangeHandoffCode(code string) (*models.UserWithToken, error) {
+	if code == "" {
+		return nil, ErrInvalidHandoffCode
+	}
+
+	handoff, err := s.handoffRepo.FindByHash(hashToken(code))
+	if err != nil {
+		return nil, err
+	}
+	if handoff == nil || handoff.ConsumedAt != nil || time.Now().After(handoff.ExpiresAt) {
+		return nil, ErrInvalidHandoffCode
+	}
+
+	consumed, err := s.handoffRepo.MarkConsumed(handoff)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidHandoffCode
+	}
+
+	payload, err := keys.Open(handoff.Payload, s.config.JWTSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var userWithToken models.UserWithToken
+	if err := json.Unmarshal(payload, &userWithToken); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar resultado do login: %w", err)
+	}
+	return &userWithToken, nil
+}
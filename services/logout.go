@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoSession indica um token de acesso sem a sessão (sid) a encerrar
+var ErrNoSession = errors.New("o token não pertence a uma sessão; use logout-all para encerrar todas as sessões")
+
+// Logout encerra a sessão atual: revoga a família de tokens de atualização e
+// os tokens de acesso emitidos para ela
+func (s *AuthService) Logout(userID, sessionID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return ErrNoSession
+	}
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		return ErrNoSession
+	}
+
+	if err := s.refreshRepo.RevokeFamily(sid); err != nil {
+		return err
+	}
+	return s.revocations.RevokeSession(sid.String(), uid)
+}
+
+// LogoutAll encerra todas as sessões do usuário
+func (s *AuthService) LogoutAll(userID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeAllForUser(uid); err != nil {
+		return err
+	}
+	return s.revocations.RevokeUser(uid)
+}
+
+// RevokeToken implementa a revogação da RFC 7009. Tokens inválidos, expirados ou
+// desconhecidos são ignorados, pois a RFC exige resposta de sucesso nesses casos.
+// O token_type_hint é apenas uma dica: o tipo real vem da claim type do token.
+func (s *AuthService) RevokeToken(token, tokenTypeHint string) error {
+	claims, err := s.parseToken(token)
+	if err != nil {
+		return nil
+	}
+
+	userID, err := uuid.Parse(stringClaim(claims, "sub"))
+	if err != nil {
+		return nil
+	}
+
+	switch stringClaim(claims, "type") {
+	case "refresh":
+		// Revogar o token de atualização encerra também os tokens de acesso da sessão
+		stored, err := s.refreshRepo.FindByHash(hashToken(token))
+		if err != nil {
+			return err
+		}
+		if stored == nil || stored.UserID != userID {
+			return nil
+		}
+		if err := s.refreshRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return err
+		}
+		return s.revocations.RevokeSession(stored.FamilyID.String(), userID)
+	case "access":
+		tokenID := stringClaim(claims, "jti")
+		if tokenID == "" {
+			return nil
+		}
+		expiresAt := time.Now().Add(AccessTokenTTL)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+		return s.revocations.RevokeAccessToken(tokenID, userID, expiresAt)
+	}
+
+	return nil
+}
+
+// stringClaim lê uma claim textual, retornando vazio se ausente
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
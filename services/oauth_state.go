@@ -0,0 +1,147 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-google/models"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// LoginStateTTL define por quanto tempo um state de login permanece válido
+const LoginStateTTL = 10 * time.Minute
+
+// ErrInvalidState indica que o parâmetro state do callback OAuth foi rejeitado
+var ErrInvalidState = errors.New("parâmetro state inválido")
+
+var (
+	errStateMissing  = fmt.Errorf("%w: ausente", ErrInvalidState)
+	errStateMismatch = fmt.Errorf("%w: não corresponde ao emitido para este navegador", ErrInvalidState)
+	errStateProvider = fmt.Errorf("%w: emitido para outro provedor", ErrInvalidState)
+	errStateUnknown  = fmt.Errorf("%w: desconhecido", ErrInvalidState)
+	errStateExpired  = fmt.Errorf("%w: expirado", ErrInvalidState)
+	errStateReplayed = fmt.Errorf("%w: já utilizado", ErrInvalidState)
+)
+
+// issueLoginState gera um novo state assinado e o registra para uso único.
+// Quando o PKCE está habilitado, o code_verifier é gerado e persistido junto ao state.
+func (s *AuthService) issueLoginState(provider, returnTo string, linkUserID, invitationID *uuid.UUID) (string, *models.LoginState, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("erro ao gerar state: %w", err)
+	}
+
+	encodedNonce := base64.RawURLEncoding.EncodeToString(nonce)
+	state := encodedNonce + "." + s.signState(encodedNonce)
+
+	// Nonce OpenID Connect, conferido no ID token retornado pelo provedor
+	oidcNonce := make([]byte, 16)
+	if _, err := rand.Read(oidcNonce); err != nil {
+		return "", nil, fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+
+	now := time.Now()
+	loginState := &models.LoginState{
+		StateHash:    hashToken(state),
+		Provider:     provider,
+		LinkUserID:   linkUserID,
+		InvitationID: invitationID,
+		ReturnTo:     sanitizeReturnTo(returnTo),
+		Nonce:        base64.RawURLEncoding.EncodeToString(oidcNonce),
+		ExpiresAt:    now.Add(LoginStateTTL),
+	}
+	if s.config.OAuthPKCEEnabled {
+		loginState.CodeVerifier = oauth2.GenerateVerifier()
+	}
+	if err := s.stateRepo.Create(loginState); err != nil {
+		return "", nil, fmt.Errorf("erro ao registrar state: %w", err)
+	}
+
+	// Limpeza oportunista dos states que nunca retornaram
+	if err := s.stateRepo.DeleteExpired(now); err != nil {
+		log.Printf("Erro ao remover states expirados: %v", err)
+	}
+
+	return state, loginState, nil
+}
+
+// consumeLoginState valida o state recebido no callback do provedor e o marca como utilizado
+func (s *AuthService) consumeLoginState(provider, state, boundState string) (*models.LoginState, error) {
+	if state == "" || boundState == "" {
+		return nil, errStateMissing
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state), []byte(boundState)) != 1 {
+		return nil, errStateMismatch
+	}
+
+	encodedNonce, signature, ok := strings.Cut(state, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signState(encodedNonce))) {
+		return nil, errStateUnknown
+	}
+
+	loginState, err := s.stateRepo.FindByHash(hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil {
+		return nil, errStateUnknown
+	}
+	if loginState.ConsumedAt != nil {
+		return nil, errStateReplayed
+	}
+	if loginState.Provider != provider {
+		return nil, errStateProvider
+	}
+	if time.Now().After(loginState.ExpiresAt) {
+		return nil, errStateExpired
+	}
+
+	consumed, err := s.stateRepo.MarkConsumed(loginState)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, errStateReplayed
+	}
+
+	return loginState, nil
+}
+
+// signState calcula a assinatura HMAC do nonce do state
+func (s *AuthService) signState(encodedNonce string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTSecret))
+	mac.Write([]byte("oauth-state:" + encodedNonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// hashToken retorna o hash SHA-256 armazenado no banco para um valor secreto emitido
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// sanitizeReturnTo aceita apenas caminhos relativos para evitar redirecionamentos abertos
+func sanitizeReturnTo(returnTo string) string {
+	if returnTo == "" || !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.Contains(returnTo, "\\") {
+		return ""
+	}
+
+	parsed, err := url.Parse(returnTo)
+	if err != nil || parsed.IsAbs() || parsed.Host != "" {
+		return ""
+	}
+
+	return returnTo
+}
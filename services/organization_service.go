@@ -0,0 +1,290 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/repository"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOrganizationNotFound indica que a organização informada não existe
+	ErrOrganizationNotFound = errors.New("organização não encontrada")
+	// ErrNotOrganizationMember indica que o usuário não participa da organização
+	ErrNotOrganizationMember = errors.New("o usuário não participa da organização")
+	// ErrMemberExists indica que o usuário já participa da organização
+	ErrMemberExists = errors.New("o usuário já participa da organização")
+	// ErrLastOrganizationAdmin impede remover o último administrador da organização
+	ErrLastOrganizationAdmin = errors.New("a organização precisa de ao menos um administrador")
+)
+
+// OrganizationService manipula a lógica de negócio das organizações e dos seus membros
+type OrganizationService struct {
+	orgRepo  *repository.OrganizationRepository
+	userRepo *repository.UserRepository
+	roleRepo *repository.RoleRepository
+	authz    *AuthzService
+	access   *AccessResolver
+}
+
+// NewOrganizationService cria um novo serviço de organizações
+func NewOrganizationService(orgRepo *repository.OrganizationRepository, userRepo *repository.UserRepository, roleRepo *repository.RoleRepository, authz *AuthzService, access *AccessResolver) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+		authz:    authz,
+		access:   access,
+	}
+}
+
+// ListOrganizations lista as organizações das quais o usuário participa, com os
+// papéis atribuídos a ele, indicando a organização ativa
+func (s *OrganizationService) ListOrganizations(userID string, activeID uuid.UUID) ([]models.OrganizationResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	memberships, err := s.orgRepo.ListMemberships(id)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := []models.OrganizationResponse{}
+	for _, membership := range memberships {
+		roles := []string{}
+		for _, role := range membership.Roles {
+			roles = append(roles, role.Name)
+		}
+		organizations = append(organizations, models.OrganizationResponse{
+			ID:     membership.OrganizationID,
+			Name:   membership.Organization.Name,
+			Roles:  roles,
+			Active: membership.OrganizationID == activeID,
+		})
+	}
+	return organizations, nil
+}
+
+// CreateOrganization cria uma organização com os papéis padrão, tendo o usuário
+// como administrador
+func (s *OrganizationService) CreateOrganization(userID string, req models.OrganizationRequest) (*models.Organization, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	organization := &models.Organization{Name: strings.TrimSpace(req.Name)}
+	if err := s.orgRepo.Create(organization, id); err != nil {
+		return nil, err
+	}
+
+	s.authz.Invalidate(userID)
+	return organization, nil
+}
+
+// GetOrganization busca uma organização pelo ID
+func (s *OrganizationService) GetOrganization(orgID uuid.UUID) (*models.Organization, error) {
+	organization, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if organization == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	return organization, nil
+}
+
+// UpdateOrganization atualiza o nome de uma organização
+func (s *OrganizationService) UpdateOrganization(orgID uuid.UUID, req models.OrganizationRequest) (*models.Organization, error) {
+	organization, err := s.GetOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	organization.Name = strings.TrimSpace(req.Name)
+	if err := s.orgRepo.Update(organization); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+// SetMemberRoles substitui os papéis de um membro da organização
+func (s *OrganizationService) SetMemberRoles(orgID uuid.UUID, userID string, roleIDs []string) (*models.UserResponse, error) {
+	membership, err := s.findMembership(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.findRoles(orgID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if hasAdminRole(membership.Roles) && !hasAdminRole(roles) {
+		if err := s.checkOtherAdmins(orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.orgRepo.SetMembershipRoles(membership, roles); err != nil {
+		return nil, err
+	}
+
+	s.authz.Invalidate(userID)
+	return s.memberResponse(orgID, userID)
+}
+
+// RemoveMember remove um usuário da organização, com seus grupos e atribuições
+// com escopo nela
+func (s *OrganizationService) RemoveMember(orgID uuid.UUID, userID string) error {
+	membership, err := s.findMembership(orgID, userID)
+	if err != nil {
+		return err
+	}
+	if hasAdminRole(membership.Roles) {
+		if err := s.checkOtherAdmins(orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.orgRepo.DeleteMembership(membership); err != nil {
+		return err
+	}
+
+	s.authz.Invalidate(userID)
+	return nil
+}
+
+// DefaultOrganization retorna a organização mais antiga do usuário, ativada no
+// login, ou uuid.Nil se ele não participa de nenhuma
+func (s *OrganizationService) DefaultOrganization(userID uuid.UUID) (uuid.UUID, error) {
+	memberships, err := s.orgRepo.ListMemberships(userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(memberships) == 0 {
+		return uuid.Nil, nil
+	}
+	return memberships[0].OrganizationID, nil
+}
+
+// IsMember indica se o usuário participa da organização
+func (s *OrganizationService) IsMember(orgID, userID uuid.UUID) (bool, error) {
+	membership, err := s.orgRepo.FindMembership(orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	return membership != nil, nil
+}
+
+// CreatePersonalOrganization cria a organização própria de um usuário recém-cadastrado
+func (s *OrganizationService) CreatePersonalOrganization(user *models.User) (uuid.UUID, error) {
+	name := user.Name
+	if name == "" {
+		name = user.Email
+	}
+
+	organization := &models.Organization{Name: name}
+	if err := s.orgRepo.Create(organization, user.ID); err != nil {
+		return uuid.Nil, err
+	}
+	return organization.ID, nil
+}
+
+// findMembership busca a associação de um usuário à organização
+func (s *OrganizationService) findMembership(orgID uuid.UUID, userID string) (*models.Membership, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	membership, err := s.orgRepo.FindMembership(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, ErrUserNotFound
+	}
+	return membership, nil
+}
+
+// memberResponse monta o perfil do membro com o acesso efetivo na organização
+func (s *OrganizationService) memberResponse(orgID uuid.UUID, userID string) (*models.UserResponse, error) {
+	user, err := s.userRepo.FindMember(orgID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	response, err := s.access.UserResponse(orgID, user)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// memberRoles busca os papéis de um novo membro, usando o papel padrão user
+// quando nenhum é informado
+func (s *OrganizationService) memberRoles(orgID uuid.UUID, roleIDs []string) ([]models.Role, error) {
+	if len(roleIDs) > 0 {
+		return s.findRoles(orgID, roleIDs)
+	}
+
+	role, err := s.roleRepo.FindByName(orgID, models.RoleUser)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return []models.Role{}, nil
+	}
+	return []models.Role{*role}, nil
+}
+
+// findRoles busca os papéis informados na organização, rejeitando IDs inválidos ou inexistentes
+func (s *OrganizationService) findRoles(orgID uuid.UUID, roleIDs []string) ([]models.Role, error) {
+	roles := []models.Role{}
+	for _, id := range roleIDs {
+		roleID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, ErrRoleNotFound
+		}
+		role, err := s.roleRepo.FindByID(orgID, roleID)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return nil, ErrRoleNotFound
+		}
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
+
+// checkOtherAdmins garante que a organização continue com um administrador
+// depois que um deles perder o papel
+func (s *OrganizationService) checkOtherAdmins(orgID uuid.UUID) error {
+	admins, err := s.orgRepo.CountAdmins(orgID)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastOrganizationAdmin
+	}
+	return nil
+}
+
+// hasAdminRole indica se os papéis incluem o papel de sistema admin
+func hasAdminRole(roles []models.Role) bool {
+	for _, role := range roles {
+		if role.System && role.Name == models.RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
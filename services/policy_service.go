@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-google/models"
+	"go-google/policy"
+	"go-google/repository"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// policyReloadInterval define por quanto tempo as políticas em memória são usadas
+// antes de serem relidas do banco (propagação de alterações entre instâncias)
+const policyReloadInterval = 5 * time.Second
+
+var (
+	// ErrPolicyNotFound indica que a política informada não existe
+	ErrPolicyNotFound = errors.New("política não encontrada")
+	// ErrPolicyExists indica que já existe uma política com o nome informado
+	ErrPolicyExists = errors.New("já existe uma política com este nome")
+	// ErrInvalidPolicy indica uma política com efeito, ações ou condições inválidas
+	ErrInvalidPolicy = errors.New("política inválida")
+)
+
+// PolicyService manipula o armazenamento e a avaliação das políticas de acesso baseado em atributos
+type PolicyService struct {
+	policyRepo *repository.PolicyRepository
+	userRepo   *repository.UserRepository
+	authz      *AuthzService
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]policySet
+}
+
+// policySet guarda as políticas de uma organização e o instante da leitura
+type policySet struct {
+	rules    []policy.Policy
+	loadedAt time.Time
+}
+
+// NewPolicyService cria um novo serviço de políticas
+func NewPolicyService(policyRepo *repository.PolicyRepository, userRepo *repository.UserRepository, authz *AuthzService) *PolicyService {
+	return &PolicyService{
+		policyRepo: policyRepo,
+		userRepo:   userRepo,
+		authz:      authz,
+		cache:      make(map[uuid.UUID]policySet),
+	}
+}
+
+// ListPolicies lista as políticas da organização
+func (s *PolicyService) ListPolicies(orgID uuid.UUID) ([]models.Policy, error) {
+	return s.policyRepo.ListAll(orgID)
+}
+
+// GetPolicy busca uma política da organização pelo ID
+func (s *PolicyService) GetPolicy(orgID uuid.UUID, id string) (*models.Policy, error) {
+	policyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrPolicyNotFound
+	}
+
+	entry, err := s.policyRepo.FindByID(orgID, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrPolicyNotFound
+	}
+	return entry, nil
+}
+
+// CreatePolicy cria uma nova política na organização
+func (s *PolicyService) CreatePolicy(orgID uuid.UUID, req models.PolicyRequest) (*models.Policy, error) {
+	entry := &models.Policy{OrganizationID: orgID}
+	if err := s.apply(entry, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(orgID)
+	return entry, nil
+}
+
+// UpdatePolicy substitui o nome, a descrição, o efeito, as ações e as condições de uma política
+func (s *PolicyService) UpdatePolicy(orgID uuid.UUID, id string, req models.PolicyRequest) (*models.Policy, error) {
+	entry, err := s.GetPolicy(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(entry, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Update(entry); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(orgID)
+	return entry, nil
+}
+
+// DeletePolicy exclui uma política
+func (s *PolicyService) DeletePolicy(orgID uuid.UUID, id string) error {
+	entry, err := s.GetPolicy(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policyRepo.Delete(entry); err != nil {
+		return err
+	}
+
+	s.invalidate(orgID)
+	return nil
+}
+
+// Evaluate avalia as políticas da organização para o usuário, a ação, os
+// atributos do recurso e o contexto da requisição. A decisão traz o ID da
+// política que a determinou.
+func (s *PolicyService) Evaluate(userID, organizationID, action string, resource, context policy.Attributes) (policy.Decision, error) {
+	orgID := parseOrganizationID(organizationID)
+	rules, err := s.loadRules(orgID)
+	if err != nil {
+		return policy.Decision{}, err
+	}
+	if len(rules) == 0 {
+		return policy.Decision{Effect: policy.NotApplicable}, nil
+	}
+
+	subject, err := s.authz.Subject(userID, orgID)
+	if err != nil {
+		return policy.Decision{}, err
+	}
+
+	return policy.Evaluate(rules, policy.Request{
+		Action:   action,
+		Subject:  subject,
+		Resource: resource,
+		Context:  context,
+	}), nil
+}
+
+// UserResource retorna os atributos de um usuário quando ele é o recurso
+// acessado: os atributos personalizados na organização, id e email. Um usuário
+// inexistente ou de fora da organização não tem atributos.
+func (s *PolicyService) UserResource(orgID uuid.UUID, id string) (policy.Attributes, error) {
+	resource := policy.Attributes{}
+	if _, err := uuid.Parse(id); err != nil {
+		return resource, nil
+	}
+
+	user, err := s.userRepo.FindMember(orgID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resource, nil
+		}
+		return nil, err
+	}
+
+	for key, value := range user.Attributes {
+		resource[key] = value
+	}
+	resource["id"] = user.ID.String()
+	resource["email"] = user.Email
+	return resource, nil
+}
+
+// apply valida a requisição e copia seus dados para a política
+func (s *PolicyService) apply(entry *models.Policy, req models.PolicyRequest) error {
+	name := strings.TrimSpace(req.Name)
+	existing, err := s.policyRepo.FindByName(entry.OrganizationID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != entry.ID {
+		return ErrPolicyExists
+	}
+
+	conditions := policy.Conditions(req.Conditions)
+	if conditions == nil {
+		conditions = policy.Conditions{}
+	}
+	rule := policy.Policy{Effect: req.Effect, Actions: req.Actions, Conditions: conditions}
+	if err := policy.Validate(rule); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidPolicy, err.Error())
+	}
+
+	entry.Name = name
+	entry.Description = req.Description
+	entry.Effect = req.Effect
+	entry.Actions = req.Actions
+	entry.Conditions = conditions
+	return nil
+}
+
+// loadRules retorna as políticas da organização em memória, relendo-as do banco quando expiram
+func (s *PolicyService) loadRules(orgID uuid.UUID) ([]policy.Policy, error) {
+	if orgID == uuid.Nil {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	set, ok := s.cache[orgID]
+	s.mu.RUnlock()
+	if ok && time.Since(set.loadedAt) < policyReloadInterval {
+		return set.rules, nil
+	}
+
+	policies, err := s.policyRepo.ListAll(orgID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]policy.Policy, 0, len(policies))
+	for i := range policies {
+		rules = append(rules, policies[i].Rule())
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = policySet{rules: rules, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return rules, nil
+}
+
+// invalidate força a releitura das políticas da organização na próxima avaliação
+func (s *PolicyService) invalidate(orgID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// AccessTokenTTL define a validade dos tokens de acesso
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL define a validade de cada token de atualização
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// errRefreshTokenRotated indica que outra requisição rotacionou o token ao mesmo tempo
+var errRefreshTokenRotated = errors.New("token de atualização já rotacionado")
+
+var (
+	// ErrInvalidRefreshToken indica um token de atualização inválido, expirado ou revogado
+	ErrInvalidRefreshToken = errors.New("token de atualização inválido")
+	// ErrRefreshTokenReused indica que um token já rotacionado foi reapresentado
+	ErrRefreshTokenReused = errors.New("token de atualização reutilizado: a sessão foi encerrada por segurança")
+)
+
+// tokenSession identifica a sessão (família de tokens de atualização) à qual
+// os tokens emitidos pertencem
+type tokenSession struct {
+	familyID uuid.UUID
+	authTime time.Time
+	client   models.ClientInfo
+	// replaces é o token rotacionado que deu origem a esta emissão
+	replaces *uuid.UUID
+}
+
+// newTokenSession inicia uma nova família de tokens após um login no provedor
+func newTokenSession(client models.ClientInfo) tokenSession {
+	return tokenSession{
+		familyID: uuid.New(),
+		authTime: time.Now(),
+		client:   client,
+	}
+}
+
+// persistRefreshToken armazena o hash do token de atualização emitido. Em uma
+// renovação, o token anterior é marcado como rotacionado na mesma transação.
+func (s *AuthService) persistRefreshToken(tokenID uuid.UUID, userID uuid.UUID, refreshToken string, expiresAt time.Time, session tokenSession) error {
+	// Limpeza oportunista dos tokens que já expiraram
+	if err := s.refreshRepo.DeleteExpired(time.Now()); err != nil {
+		log.Printf("Erro ao remover tokens de atualização expirados: %v", err)
+	}
+
+	token := &models.RefreshToken{
+		ID:        tokenID,
+		UserID:    userID,
+		FamilyID:  session.familyID,
+		TokenHash: hashToken(refreshToken),
+		UserAgent: session.client.UserAgent,
+		IPAddress: session.client.IPAddress,
+		ExpiresAt: expiresAt,
+	}
+	if session.replaces == nil {
+		return s.refreshRepo.Create(token)
+	}
+
+	rotated, err := s.refreshRepo.Rotate(*session.replaces, token)
+	if err != nil {
+		return err
+	}
+	if !rotated {
+		return errRefreshTokenRotated
+	}
+	return nil
+}
+
+// handleRefreshReuse revoga a família inteira quando um token já rotacionado é
+// reapresentado, pois isso indica que ele pode ter sido roubado
+func (s *AuthService) handleRefreshReuse(token *models.RefreshToken) error {
+	log.Printf("Reutilização de token de atualização detectada: usuário=%s família=%s", token.UserID, token.FamilyID)
+	if err := s.refreshRepo.RevokeFamily(token.FamilyID); err != nil {
+		return err
+	}
+	if err := s.revocations.RevokeSession(token.FamilyID.String(), token.UserID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-google/models"
+	"go-google/rebac"
+	"go-google/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidTuple indica um objeto, relação ou sujeito em formato inválido
+	ErrInvalidTuple = errors.New("tupla de relacionamento inválida")
+	// ErrProjectedRelation indica uma tentativa de gravar tuplas de group#member,
+	// que são projetadas das associações de grupos
+	ErrProjectedRelation = errors.New("as tuplas de group#member são projetadas dos grupos; use as rotas de membros de grupos")
+	// ErrTupleNotFound indica que a tupla informada não existe
+	ErrTupleNotFound = errors.New("tupla de relacionamento não encontrada")
+)
+
+// RelationService manipula as tuplas de relacionamento e as verificações de
+// relações. Cada organização tem as próprias tuplas, e as verificações só
+// enxergam as tuplas e os grupos da organização informada. As associações de
+// grupos (user_groups e group_parents) são projetadas como tuplas de
+// group#member, sem cópia no armazenamento de tuplas.
+type RelationService struct {
+	tupleRepo *repository.RelationTupleRepository
+	groupRepo *repository.GroupRepository
+	schema    *rebac.Schema
+}
+
+// NewRelationService cria um novo serviço de relações com o schema informado
+func NewRelationService(tupleRepo *repository.RelationTupleRepository, groupRepo *repository.GroupRepository, schema *rebac.Schema) *RelationService {
+	return &RelationService{
+		tupleRepo: tupleRepo,
+		groupRepo: groupRepo,
+		schema:    schema,
+	}
+}
+
+// Schema retorna o schema de namespaces e relações
+func (s *RelationService) Schema() *rebac.Schema {
+	return s.schema
+}
+
+// Check indica se o sujeito tem a relação com o objeto na organização
+func (s *RelationService) Check(req models.RelationTupleRequest) (bool, error) {
+	tuple, err := rebac.NewTuple(req.Object, req.Relation, req.Subject)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", ErrInvalidTuple, err.Error())
+	}
+	return s.engine(parseOrganizationID(req.OrganizationID)).Check(tuple.Object, tuple.Relation, tuple.Subject)
+}
+
+// Expand retorna a árvore de sujeitos que têm a relação com o objeto na organização
+func (s *RelationService) Expand(req models.RelationExpandRequest) (*rebac.Tree, error) {
+	object, err := rebac.ParseObject(req.Object)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTuple, err.Error())
+	}
+	return s.engine(parseOrganizationID(req.OrganizationID)).Expand(object, req.Relation)
+}
+
+// ListObjects retorna os IDs dos objetos do namespace com os quais o sujeito
+// tem a relação na organização
+func (s *RelationService) ListObjects(req models.RelationListObjectsRequest) ([]string, error) {
+	subject, err := rebac.ParseSubject(req.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTuple, err.Error())
+	}
+	return s.engine(parseOrganizationID(req.OrganizationID)).ListObjects(req.Namespace, req.Relation, subject)
+}
+
+// ListTuples lista as tuplas armazenadas da organização que atendem ao filtro.
+// As tuplas projetadas dos grupos não são listadas.
+func (s *RelationService) ListTuples(orgID uuid.UUID, object, relation, subject string) ([]rebac.Tuple, error) {
+	filter := models.RelationTupleFilter{Relation: relation}
+	if object != "" {
+		parsed, err := rebac.ParseObject(object)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTuple, err.Error())
+		}
+		filter.Namespace, filter.ObjectID = parsed.Namespace, parsed.ID
+	}
+	if subject != "" {
+		parsed, err := rebac.ParseSubject(subject)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTuple, err.Error())
+		}
+		filter.Subject = &parsed
+	}
+
+	stored, err := s.tupleRepo.List(orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+	tuples := make([]rebac.Tuple, 0, len(stored))
+	for i := range stored {
+		tuples = append(tuples, stored[i].Tuple())
+	}
+	return tuples, nil
+}
+
+// WriteTuple grava uma tupla na organização; gravar uma tupla já existente não tem efeito
+func (s *RelationService) WriteTuple(req models.RelationTupleRequest) (rebac.Tuple, error) {
+	orgID, tuple, err := s.parseWritable(req)
+	if err != nil {
+		return rebac.Tuple{}, err
+	}
+	if err := s.tupleRepo.Create(models.NewRelationTuple(orgID, tuple)); err != nil {
+		return rebac.Tuple{}, err
+	}
+	return tuple, nil
+}
+
+// DeleteTuple remove uma tupla da organização
+func (s *RelationService) DeleteTuple(req models.RelationTupleRequest) error {
+	orgID, tuple, err := s.parseWritable(req)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.tupleRepo.Delete(models.NewRelationTuple(orgID, tuple))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTupleNotFound
+	}
+	return nil
+}
+
+// engine cria o avaliador de relações restrito às tuplas e aos grupos da organização
+func (s *RelationService) engine(orgID uuid.UUID) *rebac.Engine {
+	return rebac.NewEngine(s.schema, &relationReader{service: s, orgID: orgID})
+}
+
+// relationReader implementa rebac.Reader sobre as tuplas e os grupos de uma organização
+type relationReader struct {
+	service *RelationService
+	orgID   uuid.UUID
+}
+
+// ReadTuples projeta as associações de grupos em group#member e lê as demais
+// relações das tuplas armazenadas
+func (r *relationReader) ReadTuples(object rebac.Object, relation string) ([]rebac.Tuple, error) {
+	if object.Namespace == rebac.GroupNamespace {
+		if relation != rebac.MemberRelation {
+			return nil, nil
+		}
+		return r.groupMemberTuples(object)
+	}
+
+	stored, err := r.service.tupleRepo.Find(r.orgID, object.Namespace, object.ID, relation)
+	if err != nil {
+		return nil, err
+	}
+	tuples := make([]rebac.Tuple, 0, len(stored))
+	for i := range stored {
+		tuples = append(tuples, stored[i].Tuple())
+	}
+	return tuples, nil
+}
+
+// ListObjectIDs lista os objetos do namespace com tuplas; os objetos do
+// namespace de grupos são os grupos da organização
+func (r *relationReader) ListObjectIDs(namespace string) ([]string, error) {
+	if namespace != rebac.GroupNamespace {
+		return r.service.tupleRepo.ListObjectIDs(r.orgID, namespace)
+	}
+
+	groupIDs, err := r.service.groupRepo.ListIDs(r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		ids = append(ids, id.String())
+	}
+	return ids, nil
+}
+
+// groupMemberTuples projeta os membros diretos do grupo como group:<id>#member@user:<id>
+// e os subgrupos como group:<id>#member@group:<subgrupo>#member
+func (r *relationReader) groupMemberTuples(object rebac.Object) ([]rebac.Tuple, error) {
+	groupID, err := uuid.Parse(object.ID)
+	if err != nil {
+		return nil, nil
+	}
+
+	userIDs, childIDs, err := r.service.groupRepo.FindMemberIDs(r.orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	tuples := make([]rebac.Tuple, 0, len(userIDs)+len(childIDs))
+	for _, userID := range userIDs {
+		tuples = append(tuples, rebac.Tuple{
+			Object:   object,
+			Relation: rebac.MemberRelation,
+			Subject:  rebac.Subject{Object: rebac.Object{Namespace: rebac.UserNamespace, ID: userID.String()}},
+		})
+	}
+	for _, childID := range childIDs {
+		tuples = append(tuples, rebac.Tuple{
+			Object:   object,
+			Relation: rebac.MemberRelation,
+			Subject: rebac.Subject{
+				Object:   rebac.Object{Namespace: rebac.GroupNamespace, ID: childID.String()},
+				Relation: rebac.MemberRelation,
+			},
+		})
+	}
+	return tuples, nil
+}
+
+// parseWritable interpreta a organização e a tupla e verifica se ela pode ser
+// gravada ou removida pela API
+func (s *RelationService) parseWritable(req models.RelationTupleRequest) (uuid.UUID, rebac.Tuple, error) {
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		return uuid.Nil, rebac.Tuple{}, fmt.Errorf("%w: organization_id inválido", ErrInvalidTuple)
+	}
+	tuple, err := rebac.NewTuple(req.Object, req.Relation, req.Subject)
+	if err != nil {
+		return uuid.Nil, rebac.Tuple{}, fmt.Errorf("%w: %s", ErrInvalidTuple, err.Error())
+	}
+	if tuple.Object.Namespace == rebac.GroupNamespace {
+		return uuid.Nil, rebac.Tuple{}, ErrProjectedRelation
+	}
+	if err := s.engine(orgID).ValidateTuple(tuple); err != nil {
+		return uuid.Nil, rebac.Tuple{}, err
+	}
+	return orgID, tuple, nil
+}
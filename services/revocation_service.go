@@ -0,0 +1,181 @@
+package services
+
+import (
+	"go-google/models"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// revocationRefreshInterval define de quanto em quanto tempo a lista de
+// revogações é recarregada do banco (propagação entre instâncias)
+const revocationRefreshInterval = 15 * time.Second
+
+// revocationStore persiste as revogações; implementado por repository.RevocationRepository
+type revocationStore interface {
+	Save(revocation *models.RevokedToken) error
+	ListActive(now time.Time) ([]models.RevokedToken, error)
+	DeleteExpired(before time.Time) error
+}
+
+// RevocationService mantém em memória as revogações de tokens de acesso ainda
+// válidos, para que o middleware não consulte o banco a cada requisição
+type RevocationService struct {
+	repo revocationStore
+	now  func() time.Time
+
+	mu       sync.RWMutex
+	access   map[string]time.Time
+	sessions map[string]time.Time
+	users    map[string]time.Time
+	loadedAt time.Time
+}
+
+// NewRevocationService cria um novo serviço de revogação
+func NewRevocationService(repo revocationStore) *RevocationService {
+	return &RevocationService{
+		repo:     repo,
+		now:      time.Now,
+		access:   make(map[string]time.Time),
+		sessions: make(map[string]time.Time),
+		users:    make(map[string]time.Time),
+	}
+}
+
+// IsRevoked verifica se um token de acesso foi revogado pelo jti, pela sessão
+// (sid) ou por uma revogação de todas as sessões do usuário
+func (s *RevocationService) IsRevoked(tokenID, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	if err := s.refreshIfStale(); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.access[tokenID]; ok && tokenID != "" {
+		return true, nil
+	}
+	if _, ok := s.sessions[sessionID]; ok && sessionID != "" {
+		return true, nil
+	}
+	// O iat tem precisão de segundos: os tokens emitidos no mesmo segundo da
+	// revogação também são revogados, pois podem ter sido emitidos antes dela
+	if revokedAt, ok := s.users[userID]; ok && issuedAt.Unix() <= revokedAt.Unix() {
+		return true, nil
+	}
+	return false, nil
+}
+
+// IssuedAt retorna o instante de emissão de novos tokens do usuário. Como os
+// tokens emitidos no segundo de um logout-all são revogados, um novo login
+// nesse mesmo segundo aguarda o segundo seguinte
+func (s *RevocationService) IssuedAt(userID string) time.Time {
+	s.mu.RLock()
+	revokedAt, ok := s.users[userID]
+	s.mu.RUnlock()
+
+	now := s.now()
+	if !ok || now.Unix() > revokedAt.Unix() {
+		return now
+	}
+	time.Sleep(time.Unix(revokedAt.Unix()+1, 0).Sub(now))
+	return s.now()
+}
+
+// RevokeAccessToken revoga um único token de acesso até sua expiração
+func (s *RevocationService) RevokeAccessToken(tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	return s.save(&models.RevokedToken{
+		Type:      models.RevocationAccessToken,
+		ID:        tokenID,
+		UserID:    userID,
+		RevokedAt: s.now(),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// RevokeSession revoga os tokens emitidos para uma sessão. A revogação dura o
+// tempo de vida do token de atualização, o mais longo emitido na sessão
+func (s *RevocationService) RevokeSession(sessionID string, userID uuid.UUID) error {
+	now := s.now()
+	return s.save(&models.RevokedToken{
+		Type:      models.RevocationSession,
+		ID:        sessionID,
+		UserID:    userID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	})
+}
+
+// RevokeUser revoga todos os tokens emitidos ao usuário até agora, pelo tempo de
+// vida do token de atualização
+func (s *RevocationService) RevokeUser(userID uuid.UUID) error {
+	now := s.now()
+	return s.save(&models.RevokedToken{
+		Type:      models.RevocationUser,
+		ID:        userID.String(),
+		UserID:    userID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	})
+}
+
+// save persiste a revogação e a aplica imediatamente ao cache local
+func (s *RevocationService) save(revocation *models.RevokedToken) error {
+	if err := s.repo.Save(revocation); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.apply(*revocation)
+	s.mu.Unlock()
+	return nil
+}
+
+// refreshIfStale recarrega as revogações ativas quando o cache está desatualizado
+func (s *RevocationService) refreshIfStale() error {
+	s.mu.RLock()
+	fresh := s.now().Sub(s.loadedAt) < revocationRefreshInterval
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.loadedAt) < revocationRefreshInterval {
+		return nil
+	}
+
+	revocations, err := s.repo.ListActive(now)
+	if err != nil {
+		return err
+	}
+
+	s.access = make(map[string]time.Time)
+	s.sessions = make(map[string]time.Time)
+	s.users = make(map[string]time.Time)
+	for _, revocation := range revocations {
+		s.apply(revocation)
+	}
+	s.loadedAt = now
+
+	if err := s.repo.DeleteExpired(now); err != nil {
+		log.Printf("Erro ao remover revogações expiradas: %v", err)
+	}
+	return nil
+}
+
+// apply adiciona a revogação aos mapas em memória (o chamador detém o lock)
+func (s *RevocationService) apply(revocation models.RevokedToken) {
+	switch revocation.Type {
+	case models.RevocationAccessToken:
+		s.access[revocation.ID] = revocation.ExpiresAt
+	case models.RevocationSession:
+		s.sessions[revocation.ID] = revocation.ExpiresAt
+	case models.RevocationUser:
+		s.users[revocation.ID] = revocation.RevokedAt
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"go-google/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeRevocationStore simula o repositório de revogações
+type fakeRevocationStore struct {
+	revocations []models.RevokedToken
+}
+
+func (f *fakeRevocationStore) Save(revocation *models.RevokedToken) error {
+	f.revocations = append(f.revocations, *revocation)
+	return nil
+}
+
+func (f *fakeRevocationStore) ListActive(now time.Time) ([]models.RevokedToken, error) {
+	active := []models.RevokedToken{}
+	for _, revocation := range f.revocations {
+		if revocation.ExpiresAt.After(now) {
+			active = append(active, revocation)
+		}
+	}
+	return active, nil
+}
+
+func (f *fakeRevocationStore) DeleteExpired(before time.Time) error {
+	f.revocations, _ = f.ListActive(before)
+	return nil
+}
+
+// testRevocations cria o serviço com um relógio controlado pelo teste
+func testRevocations(now time.Time) (*RevocationService, *time.Time) {
+	service := NewRevocationService(&fakeRevocationStore{})
+	service.now = func() time.Time { return now }
+	return service, &now
+}
+
+func TestIsRevokedByUserRevocation(t *testing.T) {
+	userID := "user-1"
+	revokedAt := time.Date(2026, 1, 1, 12, 0, 0, 700*int(time.Millisecond), time.UTC)
+	service := &RevocationService{
+		now:      time.Now,
+		access:   map[string]time.Time{},
+		sessions: map[string]time.Time{},
+		users:    map[string]time.Time{userID: revokedAt},
+		loadedAt: time.Now(),
+	}
+
+	// O iat dos tokens tem precisão de segundos
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"emitido antes da revogação", revokedAt.Add(-time.Second).Truncate(time.Second), true},
+		{"emitido no mesmo segundo da revogação", revokedAt.Truncate(time.Second), true},
+		{"emitido depois da revogação", revokedAt.Add(time.Second).Truncate(time.Second), false},
+		{"sem iat", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := service.IsRevoked("jti", "sid", userID, tt.issuedAt)
+			if err != nil {
+				t.Fatalf("IsRevoked: %v", err)
+			}
+			if revoked != tt.want {
+				t.Errorf("revogado = %v, esperado %v", revoked, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssuedAtAfterUserRevocation(t *testing.T) {
+	userID := uuid.New()
+	service := NewRevocationService(&fakeRevocationStore{})
+	if err := service.RevokeUser(userID); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+
+	// Um novo login logo após o logout-all emite tokens que não são revogados
+	issuedAt := service.IssuedAt(userID.String())
+	revoked, err := service.IsRevoked("jti", "sid", userID.String(), issuedAt.Truncate(time.Second))
+	if err != nil {
+		t.Fatalf("IsRevoked: %v", err)
+	}
+	if revoked {
+		t.Errorf("token emitido em %v após o logout-all foi considerado revogado", issuedAt)
+	}
+}
+
+func TestSessionAndUserRevocationsOutliveAccessTokens(t *testing.T) {
+	userID := uuid.New()
+	sessionID := uuid.NewString()
+	revokedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	issuedAt := revokedAt.Add(-time.Minute)
+
+	service, now := testRevocations(revokedAt)
+	if err := service.RevokeSession(sessionID, userID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if err := service.RevokeUser(userID); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+
+	isRevoked := func(sessionID, userID string) bool {
+		t.Helper()
+		revoked, err := service.IsRevoked("jti", sessionID, userID, issuedAt)
+		if err != nil {
+			t.Fatalf("IsRevoked: %v", err)
+		}
+		return revoked
+	}
+
+	// Passado o tempo de vida do token de acesso, as revogações recarregadas do
+	// banco continuam valendo para os tokens de atualização da sessão
+	*now = revokedAt.Add(AccessTokenTTL + time.Minute)
+	if !isRevoked(sessionID, "") {
+		t.Error("a sessão deveria continuar revogada após o tempo de vida do token de acesso")
+	}
+	if !isRevoked("", userID.String()) {
+		t.Error("o usuário deveria continuar revogado após o tempo de vida do token de acesso")
+	}
+
+	// Expirados os tokens de atualização, as revogações são descartadas
+	*now = revokedAt.Add(RefreshTokenTTL + time.Minute)
+	if isRevoked(sessionID, "") || isRevoked("", userID.String()) {
+		t.Error("as revogações deveriam expirar com o tempo de vida do token de atualização")
+	}
+}
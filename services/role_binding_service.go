@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"go-google/models"
+	"go-google/repository"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRoleBindingNotFound indica que a atribuição informada não existe
+	ErrRoleBindingNotFound = errors.New("atribuição de papel não encontrada")
+	// ErrRoleBindingExists indica que o sujeito já tem o papel sobre o recurso
+	ErrRoleBindingExists = errors.New("o sujeito já tem este papel sobre o recurso")
+	// ErrInvalidSubjectType indica um tipo de sujeito diferente de user e group
+	ErrInvalidSubjectType = errors.New("tipo de sujeito inválido: use user ou group")
+	// ErrInvalidResource indica um tipo ou ID de recurso inválido
+	ErrInvalidResource = errors.New("recurso inválido: informe o tipo (letras minúsculas, números, _, . ou -) e o ID")
+)
+
+// resourceTypePattern define os caracteres aceitos no tipo de recurso, os mesmos
+// de um segmento de permissão
+var resourceTypePattern = regexp.MustCompile(`^[a-z0-9_.-]+$`)
+
+// RoleBindingService manipula a lógica de negócio das atribuições de papel com escopo
+type RoleBindingService struct {
+	bindingRepo *repository.RoleBindingRepository
+	roleRepo    *repository.RoleRepository
+	groupRepo   *repository.GroupRepository
+	userRepo    *repository.UserRepository
+}
+
+// NewRoleBindingService cria um novo serviço de atribuições de papel com escopo
+func NewRoleBindingService(bindingRepo *repository.RoleBindingRepository, roleRepo *repository.RoleRepository, groupRepo *repository.GroupRepository, userRepo *repository.UserRepository) *RoleBindingService {
+	return &RoleBindingService{
+		bindingRepo: bindingRepo,
+		roleRepo:    roleRepo,
+		groupRepo:   groupRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// ListBindings lista as atribuições da organização que atendem ao filtro
+func (s *RoleBindingService) ListBindings(orgID uuid.UUID, filter models.RoleBindingFilter) ([]models.RoleBinding, error) {
+	if filter.SubjectID != "" {
+		if _, err := uuid.Parse(filter.SubjectID); err != nil {
+			return []models.RoleBinding{}, nil
+		}
+	}
+	return s.bindingRepo.List(orgID, filter)
+}
+
+// CreateBinding atribui um papel da organização a um membro ou grupo dela sobre um recurso
+func (s *RoleBindingService) CreateBinding(orgID uuid.UUID, req models.RoleBindingRequest) (*models.RoleBinding, error) {
+	resourceType := strings.TrimSpace(req.ResourceType)
+	resourceID := strings.TrimSpace(req.ResourceID)
+	if !resourceTypePattern.MatchString(resourceType) || resourceID == "" {
+		return nil, ErrInvalidResource
+	}
+
+	subjectID, err := s.findSubject(orgID, req.SubjectType, req.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	role, err := s.roleRepo.FindByID(orgID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	existing, err := s.bindingRepo.List(orgID, models.RoleBindingFilter{
+		SubjectType:  req.SubjectType,
+		SubjectID:    subjectID.String(),
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, binding := range existing {
+		if binding.RoleID == role.ID {
+			return nil, ErrRoleBindingExists
+		}
+	}
+
+	binding := &models.RoleBinding{
+		OrganizationID: orgID,
+		SubjectType:    req.SubjectType,
+		SubjectID:      subjectID,
+		RoleID:         role.ID,
+		Role:           *role,
+		ResourceType:   resourceType,
+		ResourceID:     resourceID,
+	}
+	if err := s.bindingRepo.Create(binding); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
+// DeleteBinding exclui uma atribuição da organização
+func (s *RoleBindingService) DeleteBinding(orgID uuid.UUID, id string) error {
+	bindingID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrRoleBindingNotFound
+	}
+
+	binding, err := s.bindingRepo.FindByID(orgID, bindingID)
+	if err != nil {
+		return err
+	}
+	if binding == nil {
+		return ErrRoleBindingNotFound
+	}
+	return s.bindingRepo.Delete(binding)
+}
+
+// findSubject valida o tipo do sujeito e verifica se o usuário ou grupo existe na organização
+func (s *RoleBindingService) findSubject(orgID uuid.UUID, subjectType, id string) (uuid.UUID, error) {
+	var notFound error
+	switch subjectType {
+	case models.SubjectUser:
+		notFound = ErrUserNotFound
+	case models.SubjectGroup:
+		notFound = ErrGroupNotFound
+	default:
+		return uuid.Nil, ErrInvalidSubjectType
+	}
+
+	subjectID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, notFound
+	}
+
+	if subjectType == models.SubjectUser {
+		_, err = s.userRepo.FindMember(orgID, id)
+	} else {
+		_, err = s.groupRepo.FindByID(orgID, id)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, notFound
+		}
+		return uuid.Nil, err
+	}
+	return subjectID, nil
+}
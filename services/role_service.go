@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-google/models"
+	"go-google/permission"
+	"go-google/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRoleNotFound indica que o papel informado não existe
+	ErrRoleNotFound = errors.New("papel não encontrado")
+	// ErrRoleExists indica que já existe um papel com o nome informado
+	ErrRoleExists = errors.New("já existe um papel com este nome")
+	// ErrSystemRole indica uma tentativa de excluir ou renomear um papel do sistema
+	ErrSystemRole = errors.New("papéis do sistema não podem ser excluídos nem renomeados")
+	// ErrUnknownPermission indica que o papel referencia permissões fora do catálogo
+	ErrUnknownPermission = errors.New("permissões não registradas no catálogo")
+	// ErrPermissionExists indica que a permissão já está registrada no catálogo
+	ErrPermissionExists = errors.New("permissão já registrada no catálogo")
+	// ErrParentRoleNotFound indica que um dos papéis pais informados não existe
+	ErrParentRoleNotFound = errors.New("papel pai não encontrado")
+	// ErrRoleCycle indica que os papéis pais informados formariam um ciclo de herança
+	ErrRoleCycle = errors.New("a herança de papéis não pode formar um ciclo")
+	// ErrInvalidPermissionName indica um nome de permissão fora do formato recurso:ação
+	ErrInvalidPermissionName = errors.New("nome de permissão inválido: use o formato recurso:ação")
+)
+
+// RoleService manipula a lógica de negócio relacionada a papéis e ao catálogo de permissões
+type RoleService struct {
+	roleRepo       *repository.RoleRepository
+	permissionRepo *repository.PermissionRepository
+	authz          *AuthzService
+}
+
+// NewRoleService cria um novo serviço de papéis
+func NewRoleService(roleRepo *repository.RoleRepository, permissionRepo *repository.PermissionRepository, authz *AuthzService) *RoleService {
+	return &RoleService{
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		authz:          authz,
+	}
+}
+
+// SeedCatalog registra as permissões dos módulos do sistema e marca os papéis padrão como papéis do sistema
+func (s *RoleService) SeedCatalog() error {
+	if err := s.permissionRepo.EnsureAll(models.GetDefaultPermissions()); err != nil {
+		return err
+	}
+
+	var names []string
+	for _, role := range models.GetDefaultRoles() {
+		names = append(names, role.Name)
+	}
+	return s.roleRepo.MarkSystemRoles(names)
+}
+
+// ListRoles lista os papéis da organização
+func (s *RoleService) ListRoles(orgID uuid.UUID) ([]models.Role, error) {
+	return s.roleRepo.ListAll(orgID)
+}
+
+// GetRole busca um papel da organização pelo ID
+func (s *RoleService) GetRole(orgID uuid.UUID, id string) (*models.Role, error) {
+	roleID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	role, err := s.roleRepo.FindByID(orgID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole cria um novo papel na organização com permissões do catálogo
+func (s *RoleService) CreateRole(orgID uuid.UUID, req models.RoleRequest) (*models.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkNameAvailable(orgID, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Um papel novo não tem herdeiros, então seus pais não podem formar ciclo
+	parents, err := s.findParents(orgID, req.ParentIDs, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		OrganizationID: orgID,
+		Name:           name,
+		Description:    req.Description,
+		Permissions:    permissions,
+		Parents:        parents,
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole substitui o nome, a descrição, as permissões e os papéis pais de um
+// papel. Papéis do sistema podem ter as permissões alteradas, mas não podem ser renomeados.
+func (s *RoleService) UpdateRole(orgID uuid.UUID, id string, req models.RoleRequest) (*models.Role, error) {
+	role, err := s.GetRole(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name != role.Name {
+		if role.System {
+			return nil, ErrSystemRole
+		}
+		if err := s.checkNameAvailable(orgID, name, role.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	permissions, err := s.validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := s.findParents(orgID, req.ParentIDs, role.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name = name
+	role.Description = req.Description
+	role.Permissions = permissions
+	role.Parents = parents
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+
+	s.authz.InvalidateAll()
+	return role, nil
+}
+
+// DeleteRole exclui um papel, removendo-o de usuários e grupos
+func (s *RoleService) DeleteRole(orgID uuid.UUID, id string) error {
+	role, err := s.GetRole(orgID, id)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return ErrSystemRole
+	}
+
+	if err := s.roleRepo.Delete(role); err != nil {
+		return err
+	}
+
+	s.authz.InvalidateAll()
+	return nil
+}
+
+// ListPermissions lista o catálogo de permissões
+func (s *RoleService) ListPermissions() ([]models.Permission, error) {
+	return s.permissionRepo.ListAll()
+}
+
+// RegisterPermission registra uma nova permissão no catálogo
+func (s *RoleService) RegisterPermission(req models.PermissionRequest) (*models.Permission, error) {
+	name := strings.TrimSpace(req.Name)
+	if !permission.Valid(name) {
+		return nil, ErrInvalidPermissionName
+	}
+
+	existing, err := s.permissionRepo.FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPermissionExists
+	}
+
+	entry := &models.Permission{
+		Name:        name,
+		Description: req.Description,
+		Module:      strings.TrimSpace(req.Module),
+	}
+	if err := s.permissionRepo.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// checkNameAvailable verifica se nenhum outro papel da organização usa o nome informado
+func (s *RoleService) checkNameAvailable(orgID uuid.UUID, name string, roleID uuid.UUID) error {
+	existing, err := s.roleRepo.FindByName(orgID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != roleID {
+		return ErrRoleExists
+	}
+	return nil
+}
+
+// findParents busca os papéis pais informados na organização e rejeita a herança
+// que formaria um ciclo, ou seja, quando o papel já é ancestral de algum dos pais
+func (s *RoleService) findParents(orgID uuid.UUID, parentIDs []string, roleID uuid.UUID) ([]models.Role, error) {
+	var ids []uuid.UUID
+	for _, id := range parentIDs {
+		parentID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, ErrParentRoleNotFound
+		}
+		if parentID == roleID {
+			return nil, ErrRoleCycle
+		}
+		ids = append(ids, parentID)
+	}
+
+	lineage, err := s.roleRepo.FindWithAncestors(orgID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uuid.UUID]models.Role, len(lineage))
+	for _, role := range lineage {
+		if role.ID == roleID {
+			return nil, ErrRoleCycle
+		}
+		found[role.ID] = role
+	}
+
+	parents := []models.Role{}
+	for _, id := range ids {
+		parent, ok := found[id]
+		if !ok {
+			return nil, ErrParentRoleNotFound
+		}
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+// validatePermissions remove duplicatas e garante que as permissões concretas
+// estejam no catálogo. Permissões com curingas (users:*, *:read, *) só precisam
+// ser válidas, pois cobrem permissões do catálogo e as registradas no futuro.
+func (s *RoleService) validatePermissions(permissions []string) ([]string, error) {
+	unique := []string{}
+	concrete := []string{}
+	seen := make(map[string]bool)
+	for _, perm := range permissions {
+		perm = strings.TrimSpace(perm)
+		if perm == "" || seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		unique = append(unique, perm)
+
+		if !permission.IsPattern(perm) {
+			concrete = append(concrete, perm)
+		} else if !permission.ValidPattern(perm) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPermissionName, perm)
+		}
+	}
+
+	missing, err := s.permissionRepo.FindMissing(concrete)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, strings.Join(missing, ", "))
+	}
+	return unique, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CookieOptions reúne os atributos dos cookies de sessão
+type CookieOptions struct {
+	Domain   string
+	SameSite http.SameSite
+	Secure   bool
+}
+
+// SessionCookieOptions retorna os atributos configurados para os cookies de
+// sessão. SameSite=None exige a flag Secure nos navegadores.
+func (s *AuthService) SessionCookieOptions() CookieOptions {
+	return CookieOptions{
+		Domain:   s.config.CookieDomain,
+		SameSite: s.config.CookieSameSite,
+		Secure:   s.SecureCookies() || s.config.CookieSameSite == http.SameSiteNoneMode,
+	}
+}
+
+// IssueCSRFToken gera o token CSRF da sessão (double-submit assinado): um valor
+// aleatório mais o HMAC do valor vinculado ao sid, de modo que um cookie
+// plantado por outro subdomínio não seja aceito em outra sessão
+func (s *AuthService) IssueCSRFToken(sessionID string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("erro ao gerar token CSRF: %w", err)
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(raw)
+	return value + "." + s.signCSRF(value, sessionID), nil
+}
+
+// VerifyCSRFToken verifica se o token CSRF foi emitido para a sessão informada
+func (s *AuthService) VerifyCSRFToken(token, sessionID string) bool {
+	value, signature, ok := strings.Cut(token, ".")
+	if !ok || value == "" || sessionID == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.signCSRF(value, sessionID)))
+}
+
+// SessionID retorna o sid de um token emitido pelo serviço, ou vazio se o token for inválido
+func (s *AuthService) SessionID(token string) string {
+	claims, err := s.parseToken(token)
+	if err != nil {
+		return ""
+	}
+	return stringClaim(claims, "sid")
+}
+
+// signCSRF calcula a assinatura HMAC do token CSRF para a sessão
+func (s *AuthService) signCSRF(value, sessionID string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTSecret))
+	mac.Write([]byte("csrf:" + sessionID + ":" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
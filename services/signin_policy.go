@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-google/providers"
+	"log"
+	"strings"
+)
+
+// SignInDeniedCode é o código de erro retornado quando a política de login recusa o acesso
+const SignInDeniedCode = "signin_policy_denied"
+
+// ErrSignInDenied indica que a identidade não atende à política de login configurada
+var ErrSignInDenied = errors.New("acesso negado pela política de login")
+
+// checkSignInPolicy aplica as listas de domínios e e-mails configuradas à identidade
+// autenticada. Regras baseadas em e-mail só aceitam e-mails verificados pelo provedor.
+func (s *AuthService) checkSignInPolicy(identity *providers.Identity) error {
+	email := strings.ToLower(identity.Email)
+	domain := emailDomain(email)
+
+	if containsFold(s.config.DeniedEmailDomains, domain) {
+		return s.denySignIn(identity, "domínio de e-mail bloqueado")
+	}
+
+	// Um e-mail liberado explicitamente dispensa as regras de domínio
+	if identity.EmailVerified && containsFold(s.config.AllowedEmails, email) {
+		return nil
+	}
+
+	if len(s.config.AllowedHostedDomains) > 0 {
+		if identity.Provider == providers.Google {
+			if !containsFold(s.config.AllowedHostedDomains, identity.HostedDomain) {
+				return s.denySignIn(identity, "conta fora dos domínios do Google Workspace permitidos")
+			}
+		} else if len(s.config.AllowedEmailDomains) == 0 {
+			// Os demais provedores não informam o domínio do Workspace; sem uma lista
+			// de domínios de e-mail, nada restringiria o acesso por eles
+			return s.denySignIn(identity, "provedor sem restrição de domínio configurada")
+		}
+	}
+
+	if len(s.config.AllowedEmailDomains) > 0 {
+		if !identity.EmailVerified || !containsFold(s.config.AllowedEmailDomains, domain) {
+			return s.denySignIn(identity, "domínio de e-mail não permitido")
+		}
+	}
+
+	// Com apenas a lista explícita configurada, somente os e-mails listados entram
+	if len(s.config.AllowedEmails) > 0 && len(s.config.AllowedEmailDomains) == 0 && len(s.config.AllowedHostedDomains) == 0 {
+		return s.denySignIn(identity, "e-mail não consta na lista de permitidos")
+	}
+
+	return nil
+}
+
+// checkInviteOnly impede a criação de novas contas quando o modo somente convidados está ativo
+func (s *AuthService) checkInviteOnly(identity *providers.Identity) error {
+	if s.config.SignInInviteOnly {
+		return s.denySignIn(identity, "cadastro restrito a convidados")
+	}
+	return nil
+}
+
+// denySignIn registra a recusa e retorna o erro da política
+func (s *AuthService) denySignIn(identity *providers.Identity, reason string) error {
+	log.Printf("Login negado pela política: provedor=%s subject=%s email=%s motivo=%s",
+		identity.Provider, identity.Subject, identity.Email, reason)
+	return fmt.Errorf("%w: %s", ErrSignInDenied, reason)
+}
+
+// emailDomain retorna o domínio de um endereço de e-mail
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return email[at+1:]
+}
+
+// containsFold verifica se o valor consta na lista, ignorando maiúsculas
+func containsFold(list []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
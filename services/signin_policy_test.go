@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"go-google/config"
+	"go-google/providers"
+	"testing"
+)
+
+func TestCheckSignInPolicyHostedDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   config.Config
+		identity providers.Identity
+		allowed  bool
+	}{
+		{
+			name:     "google no domínio permitido",
+			config:   config.Config{AllowedHostedDomains: []string{"example.com"}},
+			identity: providers.Identity{Provider: providers.Google, Email: "ana@example.com", EmailVerified: true, HostedDomain: "example.com"},
+			allowed:  true,
+		},
+		{
+			name:     "google fora do domínio permitido",
+			config:   config.Config{AllowedHostedDomains: []string{"example.com"}},
+			identity: providers.Identity{Provider: providers.Google, Email: "ana@gmail.com", EmailVerified: true},
+		},
+		{
+			name:     "github apenas com a lista de hd",
+			config:   config.Config{AllowedHostedDomains: []string{"example.com"}},
+			identity: providers.Identity{Provider: providers.GitHub, Email: "ana@example.com", EmailVerified: true},
+		},
+		{
+			name:     "microsoft apenas com a lista de hd",
+			config:   config.Config{AllowedHostedDomains: []string{"example.com"}},
+			identity: providers.Identity{Provider: providers.Microsoft, Email: "ana@outro.com", EmailVerified: true},
+		},
+		{
+			name: "github no domínio de e-mail permitido",
+			config: config.Config{
+				AllowedHostedDomains: []string{"example.com"},
+				AllowedEmailDomains:  []string{"example.com"},
+			},
+			identity: providers.Identity{Provider: providers.GitHub, Email: "ana@example.com", EmailVerified: true},
+			allowed:  true,
+		},
+		{
+			name: "github fora do domínio de e-mail permitido",
+			config: config.Config{
+				AllowedHostedDomains: []string{"example.com"},
+				AllowedEmailDomains:  []string{"example.com"},
+			},
+			identity: providers.Identity{Provider: providers.GitHub, Email: "ana@outro.com", EmailVerified: true},
+		},
+		{
+			name: "oidc com e-mail liberado explicitamente",
+			config: config.Config{
+				AllowedHostedDomains: []string{"example.com"},
+				AllowedEmails:        []string{"ana@outro.com"},
+			},
+			identity: providers.Identity{Provider: "oidc", Email: "ana@outro.com", EmailVerified: true},
+			allowed:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &AuthService{config: &tt.config}
+			err := service.checkSignInPolicy(&tt.identity)
+			if tt.allowed && err != nil {
+				t.Errorf("checkSignInPolicy = %v, esperado acesso permitido", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrSignInDenied) {
+				t.Errorf("checkSignInPolicy = %v, esperado ErrSignInDenied", err)
+			}
+		})
+	}
+}
@@ -1,86 +1,88 @@
 package services
 
 import (
-	"fmt"
+	"errors"
 	"go-google/models"
+	"go-google/policy"
 	"go-google/repository"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // UserService manipula a lógica de negócio relacionada a usuários
 type UserService struct {
 	userRepo  *repository.UserRepository
 	groupRepo *repository.GroupRepository
+	authz     *AuthzService
+	access    *AccessResolver
 }
 
 // NewUserService cria um novo serviço de usuário
-func NewUserService(userRepo *repository.UserRepository, groupRepo *repository.GroupRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, groupRepo *repository.GroupRepository, authz *AuthzService, access *AccessResolver) *UserService {
 	return &UserService{
 		userRepo:  userRepo,
 		groupRepo: groupRepo,
+		authz:     authz,
+		access:    access,
 	}
 }
 
-// GetUserProfile retorna o perfil do usuário
-func (s *UserService) GetUserProfile(userID string) (*models.UserResponse, error) {
-	user, err := s.userRepo.FindByID(userID)
+// GetUserProfile retorna o perfil do usuário na organização ativa. Sem
+// organização ativa, o perfil não traz grupos, papéis nem permissões.
+func (s *UserService) GetUserProfile(userID string, orgID uuid.UUID) (*models.UserResponse, error) {
+	user, err := s.userRepo.FindMember(orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		orgID = uuid.Nil
+		user, err = s.userRepo.FindByID(userID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Preparar resposta
-	userResponse := &models.UserResponse{
-		ID:          user.ID,
-		Email:       user.Email,
-		Name:        user.Name,
-		Picture:     user.Picture,
-		Groups:      []string{},
-		Roles:       []string{},
-		Permissions: []string{},
+	userResponse, err := s.access.UserResponse(orgID, user)
+	if err != nil {
+		return nil, err
 	}
+	return &userResponse, nil
+}
 
-	// Adicionar grupos
-	for _, group := range user.Groups {
-		userResponse.Groups = append(userResponse.Groups, group.Name)
+// GetUser retorna o perfil de um membro da organização pelo ID
+func (s *UserService) GetUser(orgID uuid.UUID, id string) (*models.UserResponse, error) {
+	user, err := s.findUser(orgID, id)
+	if err != nil {
+		return nil, err
 	}
 
-	// Adicionar papéis diretos e papéis dos grupos
-	roleMap := make(map[string]bool)
-	permMap := make(map[string]bool)
-
-	// Papéis diretos
-	for _, role := range user.Roles {
-		roleMap[role.Name] = true
-		for _, perm := range role.Permissions {
-			permMap[perm] = true
-		}
+	userResponse, err := s.access.UserResponse(orgID, user)
+	if err != nil {
+		return nil, err
 	}
+	return &userResponse, nil
+}
 
-	// Papéis dos grupos
-	for _, group := range user.Groups {
-		for _, role := range group.Roles {
-			roleMap[role.Name] = true
-			for _, perm := range role.Permissions {
-				permMap[perm] = true
-			}
-		}
+// UpdateUserAttributes substitui os atributos personalizados do usuário na
+// organização, avaliados pelas políticas de acesso
+func (s *UserService) UpdateUserAttributes(orgID uuid.UUID, id string, attributes policy.Attributes) (*models.UserResponse, error) {
+	user, err := s.findUser(orgID, id)
+	if err != nil {
+		return nil, err
 	}
 
-	// Converter mapas em slices
-	for role := range roleMap {
-		userResponse.Roles = append(userResponse.Roles, role)
+	if attributes == nil {
+		attributes = policy.Attributes{}
 	}
-	for perm := range permMap {
-		userResponse.Permissions = append(userResponse.Permissions, perm)
+	if err := s.userRepo.UpdateAttributes(orgID, user.ID, attributes); err != nil {
+		return nil, err
 	}
 
-	return userResponse, nil
+	s.authz.Invalidate(user.ID.String())
+	return s.GetUser(orgID, id)
 }
 
-// ListUsers lista todos os usuários
-func (s *UserService) ListUsers() ([]models.UserResponse, error) {
-	users, err := s.userRepo.ListAll()
+// ListUsers lista os membros da organização
+func (s *UserService) ListUsers(orgID uuid.UUID) ([]models.UserResponse, error) {
+	users, err := s.userRepo.ListMembers(orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -89,13 +91,15 @@ func (s *UserService) ListUsers() ([]models.UserResponse, error) {
 	for _, user := range users {
 		// Preparar resposta
 		userResponse := models.UserResponse{
-			ID:          user.ID,
-			Email:       user.Email,
-			Name:        user.Name,
-			Picture:     user.Picture,
-			Groups:      []string{},
-			Roles:       []string{},
-			Permissions: []string{},
+			ID:             user.ID,
+			OrganizationID: &orgID,
+			Email:          user.Email,
+			Name:           user.Name,
+			Picture:        user.Picture,
+			Groups:         []string{},
+			Roles:          []string{},
+			Permissions:    []string{},
+			Attributes:     user.Attributes,
 		}
 
 		// Adicionar grupos
@@ -114,42 +118,48 @@ func (s *UserService) ListUsers() ([]models.UserResponse, error) {
 	return userResponses, nil
 }
 
-// CreateGroup cria um novo grupo
-func (s *UserService) CreateGroup(req models.GroupRequest) (*models.Group, error) {
-	// Verificar se o grupo já existe
-	existingGroup, err := s.groupRepo.FindByName(req.Name)
-	if err == nil && existingGroup != nil {
-		return nil, fmt.Errorf("grupo com o nome '%s' já existe", req.Name)
-	}
-
-	// Criar grupo
-	group := &models.Group{
-		Name:        req.Name,
-		Description: req.Description,
+// AssignUserToGroups substitui os grupos da organização aos quais um membro pertence
+func (s *UserService) AssignUserToGroups(orgID uuid.UUID, userID string, groupIDs []string) error {
+	user, err := s.findUser(orgID, userID)
+	if err != nil {
+		return err
 	}
 
-	// Adicionar papéis se especificados
-	if len(req.RoleIDs) > 0 {
-		var roles []models.Role
-		for _, roleID := range req.RoleIDs {
-			id, err := uuid.Parse(roleID)
-			if err != nil {
-				continue // Ignorar IDs inválidos
+	ids := make([]uuid.UUID, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return ErrGroupNotFound
+		}
+		group, err := s.groupRepo.FindByID(orgID, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrGroupNotFound
 			}
-			roles = append(roles, models.Role{ID: id})
+			return err
 		}
-		group.Roles = roles
+		ids = append(ids, group.ID)
 	}
 
-	// Salvar grupo
-	if err := s.groupRepo.Create(group); err != nil {
-		return nil, err
+	if err := s.userRepo.AssignToGroups(orgID, user.ID, ids); err != nil {
+		return err
 	}
-
-	return group, nil
+	s.authz.Invalidate(userID)
+	return nil
 }
 
-// AssignUserToGroups atribui um usuário a grupos
-func (s *UserService) AssignUserToGroups(userID string, groupIDs []string) error {
-	return s.userRepo.AssignToGroups(userID, groupIDs)
-}
\ No newline at end of file
+// findUser busca um membro da organização pelo ID, rejeitando IDs inválidos,
+// inexistentes ou de fora da organização
+func (s *UserService) findUser(orgID uuid.UUID, id string) (*models.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindMember(orgID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}